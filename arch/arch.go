@@ -0,0 +1,82 @@
+// Package arch defines the architecture-agnostic interface implemented by
+// each instruction set back-end (arch/x86, arch/arm, arch/arm64, arch/ppc),
+// so that the lifter is not bolted directly to x86asm.
+package arch
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86/abi"
+	"github.com/pkg/errors"
+)
+
+// Inst is a decoded machine instruction. Its concrete type is
+// backend-specific (e.g. x86asm.Inst for arch/x86); backends type-assert
+// their own Inst values and never need to inspect another backend's.
+type Inst interface{}
+
+// FuncBuilder is the subset of the LLVM IR function-lifting state a backend
+// needs in order to emit instructions for the function currently being
+// lifted.
+type FuncBuilder interface {
+	// Func returns the LLVM IR function being lifted.
+	Func() *ir.Func
+	// CurrentBlock returns the LLVM IR basic block currently being
+	// populated.
+	CurrentBlock() *ir.Block
+	// SetCurrentBlock sets the LLVM IR basic block currently being
+	// populated, used when a backend starts lifting a new basic block.
+	SetCurrentBlock(block *ir.Block)
+}
+
+// Backend implements decoding, control flow analysis and LLVM IR lifting for
+// a single instruction set architecture.
+type Backend interface {
+	// Decode decodes the leading instruction in src, located at addr, and
+	// returns the decoded instruction and its length in bytes.
+	Decode(addr bin.Addr, src []byte) (inst Inst, n int, err error)
+	// IsTerminator reports whether inst ends a basic block.
+	IsTerminator(inst Inst) bool
+	// SuccessorAddrs returns the addresses inst may transfer control to
+	// (branch targets and, for conditional branches and fallthrough
+	// instructions, the address of the next instruction).
+	SuccessorAddrs(instAddr bin.Addr, inst Inst) []bin.Addr
+	// Lift translates inst into LLVM IR instructions appended to fb's
+	// current basic block.
+	Lift(instAddr bin.Addr, inst Inst, fb FuncBuilder) error
+	// Registers returns the architectural registers of the back-end, by
+	// name.
+	Registers() []string
+	// ABIs returns the calling conventions available on the back-end.
+	ABIs() []abi.Conv
+	// Bitness returns the native address width of the back-end, in number
+	// of bits (e.g. 32 or 64).
+	Bitness() int
+}
+
+// factories maps from instruction set architecture to the function
+// constructing the Backend for it, populated by the init function of each
+// concrete backend package (arch/x86, arch/arm, arch/arm64, arch/ppc) via
+// Register.
+var factories = make(map[bin.Machine]func(bitness int) Backend)
+
+// Register makes a backend available to Select under the given machine
+// architecture.
+//
+// Register is called from the init function of each concrete backend
+// package rather than referenced directly by this package, since those
+// packages import arch for the Backend interface and a direct reference
+// here would create an import cycle.
+func Register(machine bin.Machine, newBackend func(bitness int) Backend) {
+	factories[machine] = newBackend
+}
+
+// Select returns the Backend appropriate for lifting file, based on its
+// machine architecture.
+func Select(file bin.File) (Backend, error) {
+	newBackend, ok := factories[file.Machine()]
+	if !ok {
+		return nil, errors.Errorf("support for machine architecture %v not yet implemented", file.Machine())
+	}
+	return newBackend(file.Bitness()), nil
+}
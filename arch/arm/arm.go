@@ -0,0 +1,68 @@
+// Package arm implements the arch.Backend interface for the 32-bit ARM
+// architecture.
+//
+// This is currently a stub; decoding is intended to be based on
+// golang.org/x/arch/arm/armasm, mirroring arch/x86.
+package arm
+
+import (
+	"github.com/mewmew/x/arch"
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86/abi"
+	"github.com/pkg/errors"
+)
+
+// Backend implements arch.Backend for 32-bit ARM.
+type Backend struct{}
+
+// NewBackend returns a new ARM backend.
+func NewBackend() *Backend {
+	return &Backend{}
+}
+
+// Decode decodes the leading instruction in src, located at addr, and
+// returns the decoded instruction and its length in bytes.
+func (b *Backend) Decode(addr bin.Addr, src []byte) (arch.Inst, int, error) {
+	return nil, 0, errors.New("arch/arm: Decode not yet implemented")
+}
+
+// IsTerminator reports whether inst ends a basic block.
+func (b *Backend) IsTerminator(inst arch.Inst) bool {
+	return false
+}
+
+// SuccessorAddrs returns the addresses inst may transfer control to.
+func (b *Backend) SuccessorAddrs(instAddr bin.Addr, inst arch.Inst) []bin.Addr {
+	return nil
+}
+
+// Lift translates inst into LLVM IR instructions appended to fb's current
+// basic block.
+func (b *Backend) Lift(instAddr bin.Addr, inst arch.Inst, fb arch.FuncBuilder) error {
+	return errors.New("arch/arm: Lift not yet implemented")
+}
+
+// Registers returns the general-purpose registers of the ARM architecture.
+func (b *Backend) Registers() []string {
+	return []string{"R0", "R1", "R2", "R3", "R4", "R5", "R6", "R7", "R8", "R9", "R10", "R11", "R12", "SP", "LR", "PC", "CPSR"}
+}
+
+// ABIs returns the calling conventions available on ARM.
+func (b *Backend) ABIs() []abi.Conv {
+	// TODO: model AAPCS once abi.Conv grows ARM-specific conventions.
+	return nil
+}
+
+// Bitness returns the native address width of the back-end, in number of
+// bits.
+func (b *Backend) Bitness() int {
+	return 32
+}
+
+var _ arch.Backend = (*Backend)(nil)
+
+// init registers the ARM backend with arch.Select for 32-bit ARM
+// executables.
+func init() {
+	arch.Register(bin.MachineARM, func(bitness int) arch.Backend { return NewBackend() })
+}
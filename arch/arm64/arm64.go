@@ -0,0 +1,75 @@
+// Package arm64 implements the arch.Backend interface for the 64-bit ARM
+// (AArch64) architecture.
+//
+// This is currently a stub; decoding is intended to be based on
+// golang.org/x/arch/arm64/arm64asm, mirroring arch/x86.
+package arm64
+
+import (
+	"strconv"
+
+	"github.com/mewmew/x/arch"
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86/abi"
+	"github.com/pkg/errors"
+)
+
+// Backend implements arch.Backend for AArch64.
+type Backend struct{}
+
+// NewBackend returns a new AArch64 backend.
+func NewBackend() *Backend {
+	return &Backend{}
+}
+
+// Decode decodes the leading instruction in src, located at addr, and
+// returns the decoded instruction and its length in bytes.
+func (b *Backend) Decode(addr bin.Addr, src []byte) (arch.Inst, int, error) {
+	return nil, 0, errors.New("arch/arm64: Decode not yet implemented")
+}
+
+// IsTerminator reports whether inst ends a basic block.
+func (b *Backend) IsTerminator(inst arch.Inst) bool {
+	return false
+}
+
+// SuccessorAddrs returns the addresses inst may transfer control to.
+func (b *Backend) SuccessorAddrs(instAddr bin.Addr, inst arch.Inst) []bin.Addr {
+	return nil
+}
+
+// Lift translates inst into LLVM IR instructions appended to fb's current
+// basic block.
+func (b *Backend) Lift(instAddr bin.Addr, inst arch.Inst, fb arch.FuncBuilder) error {
+	return errors.New("arch/arm64: Lift not yet implemented")
+}
+
+// Registers returns the general-purpose registers of the AArch64
+// architecture.
+func (b *Backend) Registers() []string {
+	regs := make([]string, 0, 31)
+	for i := 0; i < 31; i++ {
+		regs = append(regs, "X"+strconv.Itoa(i))
+	}
+	return append(regs, "SP", "PC", "NZCV")
+}
+
+// ABIs returns the calling conventions available on AArch64.
+func (b *Backend) ABIs() []abi.Conv {
+	// TODO: model AAPCS64 once abi.Conv grows ARM-specific conventions.
+	return nil
+}
+
+// Bitness returns the native address width of the back-end, in number of
+// bits.
+func (b *Backend) Bitness() int {
+	return 64
+}
+
+var _ arch.Backend = (*Backend)(nil)
+
+// init registers the AArch64 backend with arch.Select for 64-bit ARM
+// executables.
+func init() {
+	arch.Register(bin.MachineARM64, func(bitness int) arch.Backend { return NewBackend() })
+}
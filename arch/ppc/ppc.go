@@ -0,0 +1,79 @@
+// Package ppc implements the arch.Backend interface for the PowerPC
+// architecture.
+//
+// This is currently a stub; decoding is intended to be based on
+// golang.org/x/arch/ppc64/ppc64asm, mirroring arch/x86.
+package ppc
+
+import (
+	"strconv"
+
+	"github.com/mewmew/x/arch"
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86/abi"
+	"github.com/pkg/errors"
+)
+
+// Backend implements arch.Backend for PowerPC.
+type Backend struct {
+	// bitness is the native address width (32 or 64).
+	bitness int
+}
+
+// NewBackend returns a new PowerPC backend of the given bitness (32 or 64).
+func NewBackend(bitness int) *Backend {
+	return &Backend{bitness: bitness}
+}
+
+// Decode decodes the leading instruction in src, located at addr, and
+// returns the decoded instruction and its length in bytes.
+func (b *Backend) Decode(addr bin.Addr, src []byte) (arch.Inst, int, error) {
+	return nil, 0, errors.New("arch/ppc: Decode not yet implemented")
+}
+
+// IsTerminator reports whether inst ends a basic block.
+func (b *Backend) IsTerminator(inst arch.Inst) bool {
+	return false
+}
+
+// SuccessorAddrs returns the addresses inst may transfer control to.
+func (b *Backend) SuccessorAddrs(instAddr bin.Addr, inst arch.Inst) []bin.Addr {
+	return nil
+}
+
+// Lift translates inst into LLVM IR instructions appended to fb's current
+// basic block.
+func (b *Backend) Lift(instAddr bin.Addr, inst arch.Inst, fb arch.FuncBuilder) error {
+	return errors.New("arch/ppc: Lift not yet implemented")
+}
+
+// Registers returns the general-purpose registers of the PowerPC
+// architecture.
+func (b *Backend) Registers() []string {
+	regs := make([]string, 0, 32)
+	for i := 0; i < 32; i++ {
+		regs = append(regs, "R"+strconv.Itoa(i))
+	}
+	return append(regs, "LR", "CTR", "CR", "XER")
+}
+
+// ABIs returns the calling conventions available on PowerPC.
+func (b *Backend) ABIs() []abi.Conv {
+	// TODO: model the PowerPC ELF ABI once abi.Conv grows PowerPC-specific
+	// conventions.
+	return nil
+}
+
+// Bitness returns the native address width of the back-end, in number of
+// bits (32 or 64).
+func (b *Backend) Bitness() int {
+	return b.bitness
+}
+
+var _ arch.Backend = (*Backend)(nil)
+
+// init registers the PowerPC backend with arch.Select for 64-bit PowerPC
+// executables.
+func init() {
+	arch.Register(bin.MachinePPC64, func(bitness int) arch.Backend { return NewBackend(bitness) })
+}
@@ -0,0 +1,155 @@
+// Package x86 implements the arch.Backend interface for the x86
+// architecture, wrapping the existing disasm/x86 decoder.
+package x86
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/mewmew/x/arch"
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86/abi"
+	"github.com/mewmew/x/disasm/x86/sem"
+	"github.com/pkg/errors"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// Backend implements arch.Backend for x86.
+type Backend struct {
+	// cpuMode is the x86asm decode mode (16, 32 or 64).
+	cpuMode int
+	// states tracks the register/flag state of each function currently
+	// being lifted, keyed by the LLVM IR function.
+	states map[*ir.Func]*sem.State
+}
+
+// NewBackend returns a new x86 backend decoding instructions of the given
+// bitness (16, 32 or 64).
+func NewBackend(bitness int) *Backend {
+	return &Backend{
+		cpuMode: bitness,
+		states:  make(map[*ir.Func]*sem.State),
+	}
+}
+
+// Decode decodes the leading instruction in src, located at addr, and
+// returns the decoded instruction and its length in bytes.
+func (b *Backend) Decode(addr bin.Addr, src []byte) (arch.Inst, int, error) {
+	inst, err := x86asm.Decode(src, b.cpuMode)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	return inst, inst.Len, nil
+}
+
+// IsTerminator reports whether inst ends a basic block.
+func (b *Backend) IsTerminator(inst arch.Inst) bool {
+	i := inst.(x86asm.Inst)
+	switch i.Op {
+	case x86asm.LOOP, x86asm.LOOPE, x86asm.LOOPNE,
+		x86asm.JA, x86asm.JAE, x86asm.JB, x86asm.JBE, x86asm.JCXZ, x86asm.JE, x86asm.JECXZ, x86asm.JG, x86asm.JGE, x86asm.JL, x86asm.JLE, x86asm.JNE, x86asm.JNO, x86asm.JNP, x86asm.JNS, x86asm.JO, x86asm.JP, x86asm.JRCXZ, x86asm.JS,
+		x86asm.JMP, x86asm.RET:
+		return true
+	}
+	return false
+}
+
+// SuccessorAddrs returns the addresses inst may transfer control to.
+func (b *Backend) SuccessorAddrs(instAddr bin.Addr, inst arch.Inst) []bin.Addr {
+	i := inst.(x86asm.Inst)
+	next := instAddr + bin.Addr(i.Len)
+	switch i.Op {
+	case x86asm.RET:
+		return nil
+	case x86asm.JMP:
+		if target, ok := relTarget(instAddr, i); ok {
+			return []bin.Addr{target}
+		}
+		return nil
+	case x86asm.LOOP, x86asm.LOOPE, x86asm.LOOPNE,
+		x86asm.JA, x86asm.JAE, x86asm.JB, x86asm.JBE, x86asm.JCXZ, x86asm.JE, x86asm.JECXZ, x86asm.JG, x86asm.JGE, x86asm.JL, x86asm.JLE, x86asm.JNE, x86asm.JNO, x86asm.JNP, x86asm.JNS, x86asm.JO, x86asm.JP, x86asm.JRCXZ, x86asm.JS:
+		succs := []bin.Addr{next}
+		if target, ok := relTarget(instAddr, i); ok {
+			succs = append(succs, target)
+		}
+		return succs
+	default:
+		return []bin.Addr{next}
+	}
+}
+
+// Lift translates inst into LLVM IR instructions appended to fb's current
+// basic block.
+func (b *Backend) Lift(instAddr bin.Addr, inst arch.Inst, fb arch.FuncBuilder) error {
+	i := inst.(x86asm.Inst)
+	state := b.state(fb)
+	tr := sem.NewTranslator(state, fb.CurrentBlock())
+	if err := tr.Translate(instAddr, i); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// state returns the register/flag state for the function currently being
+// lifted by fb, creating and seeding it (with allocas in the function's
+// entry block) on first use.
+func (b *Backend) state(fb arch.FuncBuilder) *sem.State {
+	f := fb.Func()
+	state, ok := b.states[f]
+	if !ok {
+		state = sem.NewState(f.Blocks[0], b.cpuMode)
+		b.states[f] = state
+	}
+	return state
+}
+
+// Registers returns the general-purpose and flag registers of the x86
+// architecture.
+func (b *Backend) Registers() []string {
+	return []string{
+		"AL", "AH", "AX", "EAX", "RAX",
+		"CL", "CH", "CX", "ECX", "RCX",
+		"DL", "DH", "DX", "EDX", "RDX",
+		"BL", "BH", "BX", "EBX", "RBX",
+		"SP", "ESP", "RSP",
+		"BP", "EBP", "RBP",
+		"SI", "ESI", "RSI",
+		"DI", "EDI", "RDI",
+		"EFLAGS", "RFLAGS",
+	}
+}
+
+// ABIs returns the calling conventions available on x86.
+func (b *Backend) ABIs() []abi.Conv {
+	if b.cpuMode == 64 {
+		return []abi.Conv{abi.SysVAMD64, abi.Win64}
+	}
+	return []abi.Conv{abi.Cdecl, abi.Stdcall, abi.Fastcall, abi.Thiscall}
+}
+
+// Bitness returns the native address width of the back-end, in number of
+// bits (16, 32 or 64).
+func (b *Backend) Bitness() int {
+	return b.cpuMode
+}
+
+// relTarget returns the absolute target address of a direct (PC-relative)
+// jump instruction.
+func relTarget(instAddr bin.Addr, inst x86asm.Inst) (bin.Addr, bool) {
+	if len(inst.Args) == 0 {
+		return 0, false
+	}
+	rel, ok := inst.Args[0].(x86asm.Rel)
+	if !ok {
+		return 0, false
+	}
+	return bin.Addr(int64(instAddr) + int64(inst.Len) + int64(rel)), true
+}
+
+var _ arch.Backend = (*Backend)(nil)
+
+// init registers the x86 backend with arch.Select for both 32-bit
+// (MachineX86) and 64-bit (MachineX86_64) executables.
+func init() {
+	newBackend := func(bitness int) arch.Backend { return NewBackend(bitness) }
+	arch.Register(bin.MachineX86, newBackend)
+	arch.Register(bin.MachineX86_64, newBackend)
+}
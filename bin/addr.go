@@ -11,19 +11,23 @@ import (
 
 // Addr is a virtual address that may be specified in hexadecimal notation. It
 // implements the flag.Value and encoding.TextUnmarshaler interfaces.
-type Addr uint32
+//
+// Addr is wide enough to hold addresses of 32- and 64-bit binary executables
+// alike; use File.Bitness to recover the native address width of a given
+// executable.
+type Addr uint64
 
 // Address size in number of bits.
-const addrSize = 32
+const addrSize = 64
 
 // String returns the hexadecimal string representation of v.
 func (v Addr) String() string {
-	return fmt.Sprintf("0x%08X", uint32(v))
+	return fmt.Sprintf("0x%08X", uint64(v))
 }
 
 // Set sets v to the numberic value represented by s.
 func (v *Addr) Set(s string) error {
-	x, err := parseUint32(s)
+	x, err := parseUint64(s)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -51,17 +55,17 @@ func (as Addrs) Less(i, j int) bool { return as[i] < as[j] }
 
 // ### [ Helper functions ] ####################################################
 
-// parseUint32 interprets the given string in base 10 or base 16 (if prefixed
+// parseUint64 interprets the given string in base 10 or base 16 (if prefixed
 // with `0x` or `0X`) and returns the corresponding value.
-func parseUint32(s string) (uint32, error) {
+func parseUint64(s string) (uint64, error) {
 	base := 10
 	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
 		s = s[len("0x"):]
 		base = 16
 	}
-	x, err := strconv.ParseUint(s, base, 32)
+	x, err := strconv.ParseUint(s, base, addrSize)
 	if err != nil {
 		return 0, errors.WithStack(err)
 	}
-	return uint32(x), nil
+	return x, nil
 }
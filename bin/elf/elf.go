@@ -0,0 +1,150 @@
+// Package elf provides access to ELF binaries, exposed through the bin.File
+// interface.
+package elf
+
+import (
+	"debug/elf"
+
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+)
+
+// File is an ELF binary executable.
+type File struct {
+	// Underlying ELF file.
+	file *elf.File
+	// Native address width in number of bits (32 or 64).
+	bitness int
+	// Instruction set architecture targeted by the executable.
+	machine bin.Machine
+}
+
+// Open opens the ELF binary executable at the given path.
+func Open(path string) (*File, error) {
+	file, err := elf.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &File{file: file}
+	switch file.Class {
+	case elf.ELFCLASS32:
+		f.bitness = 32
+	case elf.ELFCLASS64:
+		f.bitness = 64
+	default:
+		return nil, errors.Errorf("support for ELF class %v not yet implemented", file.Class)
+	}
+	f.machine = machineOf(file.Machine)
+	return f, nil
+}
+
+// machineOf converts an ELF e_machine constant to a bin.Machine.
+func machineOf(m elf.Machine) bin.Machine {
+	switch m {
+	case elf.EM_386:
+		return bin.MachineX86
+	case elf.EM_X86_64:
+		return bin.MachineX86_64
+	case elf.EM_ARM:
+		return bin.MachineARM
+	case elf.EM_AARCH64:
+		return bin.MachineARM64
+	case elf.EM_PPC64:
+		return bin.MachinePPC64
+	default:
+		return bin.MachineUnknown
+	}
+}
+
+// Bitness returns the native address width of the executable, in number of
+// bits (32 or 64).
+func (f *File) Bitness() int {
+	return f.bitness
+}
+
+// Machine returns the instruction set architecture the executable targets.
+func (f *File) Machine() bin.Machine {
+	return f.machine
+}
+
+// ImageBase returns the preferred load address of the executable image.
+func (f *File) ImageBase() bin.Addr {
+	// ELF executables do not record a single preferred image base; segments
+	// are mapped at their own virtual addresses.
+	return 0
+}
+
+// Entry returns the address of the entry point of the executable.
+func (f *File) Entry() bin.Addr {
+	return bin.Addr(f.file.Entry)
+}
+
+// Sections returns the sections of the executable.
+func (f *File) Sections() ([]bin.Section, error) {
+	var sects []bin.Section
+	for _, sect := range f.file.Sections {
+		if sect.Flags&elf.SHF_ALLOC == 0 {
+			continue
+		}
+		data, err := sect.Data()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		perm := bin.PermR
+		if sect.Flags&elf.SHF_WRITE != 0 {
+			perm |= bin.PermW
+		}
+		if sect.Flags&elf.SHF_EXECINSTR != 0 {
+			perm |= bin.PermX
+		}
+		sects = append(sects, bin.Section{
+			Name: sect.Name,
+			Addr: bin.Addr(sect.Addr),
+			Data: data,
+			Perm: perm,
+		})
+	}
+	return sects, nil
+}
+
+// Symbols returns the symbol table of the executable, or nil if the
+// executable contains no symbol information.
+func (f *File) Symbols() []bin.Symbol {
+	elfSyms, err := f.file.Symbols()
+	if err != nil {
+		return nil
+	}
+	var syms []bin.Symbol
+	for _, elfSym := range elfSyms {
+		syms = append(syms, bin.Symbol{
+			Name: elfSym.Name,
+			Addr: bin.Addr(elfSym.Value),
+		})
+	}
+	return syms
+}
+
+// Imports returns the symbols imported by the executable.
+func (f *File) Imports() []bin.Symbol {
+	elfSyms, err := f.file.ImportedSymbols()
+	if err != nil {
+		return nil
+	}
+	var syms []bin.Symbol
+	for _, elfSym := range elfSyms {
+		syms = append(syms, bin.Symbol{Name: elfSym.Name})
+	}
+	return syms
+}
+
+// Exports returns the symbols exported by the executable.
+func (f *File) Exports() []bin.Symbol {
+	// TODO: filter Symbols for exported (global, default-visibility) symbols
+	// once dynamic symbol versioning is taken into account.
+	return nil
+}
+
+// Close closes the executable.
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,101 @@
+package bin
+
+// File is a binary executable, in any of the formats supported by the bin
+// sub-packages (bin/pe, bin/elf, bin/macho).
+//
+// File abstracts over the parts of an executable that the lifter cares about,
+// so that the x86 front-end (and future architecture back-ends) do not need
+// to know whether they are lifting a Windows PE, a Linux ELF or a Mach-O
+// image.
+type File interface {
+	// Bitness returns the native address width of the executable, in number
+	// of bits (32 or 64).
+	Bitness() int
+	// Machine returns the instruction set architecture the executable
+	// targets.
+	Machine() Machine
+	// ImageBase returns the preferred load address of the executable image.
+	ImageBase() Addr
+	// Entry returns the address of the entry point of the executable.
+	Entry() Addr
+	// Sections returns the sections of the executable.
+	Sections() ([]Section, error)
+	// Symbols returns the symbol table of the executable, or nil if the
+	// executable contains no symbol information.
+	Symbols() []Symbol
+	// Imports returns the symbols imported by the executable.
+	Imports() []Symbol
+	// Exports returns the symbols exported by the executable.
+	Exports() []Symbol
+	// Close closes the executable.
+	Close() error
+}
+
+// Machine identifies the instruction set architecture a binary executable
+// targets, recovered from the format-specific machine/CPU-type field of its
+// header (e.g. PE's IMAGE_FILE_MACHINE_*, ELF's e_machine, Mach-O's cputype).
+type Machine uint8
+
+// Instruction set architectures.
+const (
+	// MachineUnknown is an unrecognized or not yet supported architecture.
+	MachineUnknown Machine = iota
+	// MachineX86 is 32-bit x86.
+	MachineX86
+	// MachineX86_64 is 64-bit x86 (AMD64/Intel 64).
+	MachineX86_64
+	// MachineARM is 32-bit ARM.
+	MachineARM
+	// MachineARM64 is 64-bit ARM (AArch64).
+	MachineARM64
+	// MachinePPC64 is 64-bit PowerPC.
+	MachinePPC64
+)
+
+// String returns the display name of the machine architecture.
+func (m Machine) String() string {
+	switch m {
+	case MachineX86:
+		return "x86"
+	case MachineX86_64:
+		return "x86_64"
+	case MachineARM:
+		return "ARM"
+	case MachineARM64:
+		return "ARM64"
+	case MachinePPC64:
+		return "PPC64"
+	default:
+		return "unknown"
+	}
+}
+
+// Section is a section of a binary executable.
+type Section struct {
+	// Section name.
+	Name string
+	// Address of the section, as mapped into memory.
+	Addr Addr
+	// Contents of the section.
+	Data []byte
+	// Perm specifies the permissions of the section (read, write, execute).
+	Perm Perm
+}
+
+// Perm specifies the access permissions of a section.
+type Perm uint8
+
+// Access permissions.
+const (
+	PermR Perm = 1 << iota
+	PermW
+	PermX
+)
+
+// Symbol is a symbol of a binary executable.
+type Symbol struct {
+	// Symbol name.
+	Name string
+	// Address of the symbol.
+	Addr Addr
+}
@@ -0,0 +1,117 @@
+package gobin
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// buildInfoMagic is the sentinel that precedes the build info blob embedded
+// by the Go linker, as used by `go version -m`.
+var buildInfoMagic = []byte("\xff Go buildinf:")
+
+// BuildInfo holds the toolchain version and the main module's path and
+// version recorded by the Go linker in the build info blob. Dependencies and
+// build settings are not recorded.
+type BuildInfo struct {
+	// GoVersion is the version of the Go toolchain used to build the binary.
+	GoVersion string
+	// Path is the main module's package path.
+	Path string
+	// Version is the main module's version ("(devel)" for non-release
+	// builds).
+	Version string
+}
+
+// ParseBuildInfo parses the build info blob starting with the
+// "\xff Go buildinf:" sentinel.
+//
+// The blob layout (after the 14-byte magic) is a 1-byte pointer size, a
+// 1-byte flags field, and then either a pair of pointer-sized offset+length
+// references into the binary's data (flagsVersionInl unset, used by Go
+// versions before 1.18) or, padded out to a 32-byte-aligned header,
+// inlined "go version" / "mod info" strings (flagsVersionInl set, used by
+// modern Go toolchains). Only the inline layout is decoded here, since the
+// offset-based layout points into the binary's data section and requires
+// access to the full file to resolve; that is left as a TODO for a
+// follow-up that threads bin.File through this parser.
+func ParseBuildInfo(data []byte) (*BuildInfo, error) {
+	const (
+		magicLen = 14
+		// hdrSize is the offset of the inlined strings, the build info
+		// header padded out to the Go linker's 32-byte buildInfoAlign.
+		hdrSize = 32
+		// flagsVersionInl marks the modern, inline-string layout; when
+		// unset, the two strings following the flags byte are offset+length
+		// pairs into the binary's data instead.
+		flagsVersionInl = 0x2
+	)
+	if len(data) < magicLen+2 {
+		return nil, errors.New("build info blob truncated before pointer size/flags fields")
+	}
+	flags := data[magicLen+1]
+	if flags&flagsVersionInl == 0 {
+		return nil, errors.New("support for pointer-based (pre-Go 1.18) build info not yet implemented")
+	}
+	if len(data) < hdrSize {
+		return nil, errors.New("build info blob truncated before inlined version/mod info strings")
+	}
+	rest := data[hdrSize:]
+	goVersion, rest, err := readLengthPrefixedString(rest)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	modInfo, _, err := readLengthPrefixedString(rest)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	bi := &BuildInfo{GoVersion: goVersion}
+	bi.Path, bi.Version = parseModInfo(modInfo)
+	return bi, nil
+}
+
+// parseModInfo extracts the main module's package path and version from
+// modInfo, the newline-separated, tab-delimited dependency listing recorded
+// by cmd/go (the same text runtime/debug.BuildInfo.String() would produce):
+//
+//	path	example.com/cmd
+//	mod	example.com/cmd	v1.2.3	h1:...=
+//	dep	example.com/dep	v0.1.0	h1:...=
+//	build	-compiler=gc
+func parseModInfo(modInfo string) (path, version string) {
+	const pathPrefix = "path\t"
+	const modPrefix = "mod\t"
+	for _, line := range strings.Split(modInfo, "\n") {
+		switch {
+		case strings.HasPrefix(line, pathPrefix):
+			path = strings.TrimPrefix(line, pathPrefix)
+		case version == "" && strings.HasPrefix(line, modPrefix):
+			// The first "mod" line records the main module itself.
+			fields := strings.Split(strings.TrimPrefix(line, modPrefix), "\t")
+			if len(fields) >= 2 {
+				version = fields[1]
+			}
+		}
+	}
+	return path, version
+}
+
+// readLengthPrefixedString reads a Go string encoded as a varint length
+// followed by that many bytes, as used by the inline (flagsVersionInl) build
+// info layout.
+func readLengthPrefixedString(data []byte) (string, []byte, error) {
+	size := binary.MaxVarintLen64
+	if size > len(data) {
+		size = len(data)
+	}
+	length, nbytes := binary.Uvarint(data[:size])
+	if nbytes <= 0 {
+		return "", nil, errors.New("unable to decode build info string length")
+	}
+	data = data[nbytes:]
+	if uint64(len(data)) < length {
+		return "", nil, errors.New("build info string truncated")
+	}
+	return string(data[:length]), data[length:], nil
+}
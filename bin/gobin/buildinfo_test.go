@@ -0,0 +1,60 @@
+package gobin
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// putLengthPrefixedString appends a varint length prefix followed by s to
+// buf, the encoding readLengthPrefixedString expects.
+func putLengthPrefixedString(buf []byte, s string) []byte {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(s)))
+	buf = append(buf, length[:n]...)
+	return append(buf, s...)
+}
+
+// buildBuildInfoBlob builds a build info blob using the modern, inline-string
+// layout (flagsVersionInl set).
+func buildBuildInfoBlob(goVersion, modInfo string) []byte {
+	const hdrSize = 32
+	data := make([]byte, hdrSize)
+	copy(data, buildInfoMagic)
+	data[15] = 0x2 // flagsVersionInl
+	data = putLengthPrefixedString(data, goVersion)
+	data = putLengthPrefixedString(data, modInfo)
+	return data
+}
+
+func TestParseBuildInfo(t *testing.T) {
+	modInfo := "path\texample.com/cmd\n" +
+		"mod\texample.com/cmd\tv1.2.3\th1:abc=\n" +
+		"dep\texample.com/dep\tv0.1.0\th1:def=\n" +
+		"build\t-compiler=gc\n"
+	data := buildBuildInfoBlob("go1.21.6", modInfo)
+	bi, err := ParseBuildInfo(data)
+	if err != nil {
+		t.Fatalf("ParseBuildInfo: %v", err)
+	}
+	if bi.GoVersion != "go1.21.6" {
+		t.Errorf("GoVersion: got %q, want %q", bi.GoVersion, "go1.21.6")
+	}
+	if bi.Path != "example.com/cmd" {
+		t.Errorf("Path: got %q, want %q", bi.Path, "example.com/cmd")
+	}
+	if bi.Version != "v1.2.3" {
+		t.Errorf("Version: got %q, want %q", bi.Version, "v1.2.3")
+	}
+}
+
+// TestParseBuildInfoPointerBasedRejected verifies that the pre-Go 1.18
+// pointer-based layout (flagsVersionInl unset) is reported as unimplemented
+// rather than misparsed as inline strings.
+func TestParseBuildInfoPointerBasedRejected(t *testing.T) {
+	data := make([]byte, 32)
+	copy(data, buildInfoMagic)
+	data[15] = 0x0
+	if _, err := ParseBuildInfo(data); err == nil {
+		t.Fatal("ParseBuildInfo: expected error for pointer-based build info, got nil")
+	}
+}
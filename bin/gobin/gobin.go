@@ -0,0 +1,93 @@
+// Package gobin recognizes Go-produced PE/ELF/Mach-O binaries and recovers
+// the function symbol table, module path and version embedded by the Go
+// linker, so the lifter can key functions off their real Go names instead of
+// generic "func_%08X" placeholders.
+package gobin
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"github.com/mewkiz/pkg/term"
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+)
+
+// warn is a logger which logs warning messages with "warning:" prefix to
+// standard error.
+var warn = log.New(os.Stderr, term.RedBold("warning:")+" ", 0)
+
+// pclntabSectionNames are the section names used to hold the pclntab across
+// the supported binary formats.
+var pclntabSectionNames = []string{".gopclntab", "__gopclntab"}
+
+// Info holds the Go-specific metadata recovered from a Go binary executable.
+type Info struct {
+	// BuildInfo is the toolchain version and main module path/version
+	// recorded by the Go linker, or nil if not found.
+	BuildInfo *BuildInfo
+	// PCLNTab is the parsed program counter line table, used to recover
+	// function names and address ranges.
+	PCLNTab *PCLNTab
+}
+
+// IsGoBinary reports whether file appears to be a Go-produced binary
+// executable, based on the presence of a .gopclntab (or __gopclntab)
+// section.
+func IsGoBinary(file bin.File) (bool, error) {
+	sects, err := file.Sections()
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	for _, sect := range sects {
+		for _, name := range pclntabSectionNames {
+			if sect.Name == name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// Parse recovers the Go-specific metadata embedded in file: the pclntab
+// (function names and entry addresses) and the build info blob (toolchain
+// version, main module path and version).
+func Parse(file bin.File) (*Info, error) {
+	sects, err := file.Sections()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	info := &Info{}
+	for _, sect := range sects {
+		for _, name := range pclntabSectionNames {
+			if sect.Name == name {
+				tab, err := ParsePCLNTab(sect.Data)
+				if err != nil {
+					// A pre-1.16 pclntab (headerless layout) or any other
+					// unparseable .gopclntab should not abort recovery of a
+					// Go binary outright; callers fall back to JSON-sourced
+					// or symbol-table function addresses instead, as
+					// advertised by ParsePCLNTab's doc comment.
+					warn.Printf("unable to parse Go pclntab: %v", err)
+					continue
+				}
+				info.PCLNTab = tab
+			}
+		}
+	}
+	for _, sect := range sects {
+		if idx := bytes.Index(sect.Data, buildInfoMagic); idx != -1 {
+			bi, err := ParseBuildInfo(sect.Data[idx:])
+			if err != nil {
+				// Build info is a nice-to-have; do not fail the whole parse
+				// over a malformed/truncated sentinel.
+				warn.Printf("unable to parse Go build info: %v", err)
+				continue
+			}
+			info.BuildInfo = bi
+			break
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,203 @@
+package gobin
+
+import (
+	"encoding/binary"
+
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+)
+
+// pclntab magic numbers, one per Go toolchain generation. Only the Go
+// 1.16+ headers (pcHeaderMagicGo116, pcHeaderMagicGo118, pcHeaderMagicGo120)
+// are decoded below;
+// the older, headerless layout is recognized but rejected with a clear
+// error so callers can fall back to JSON-sourced function addresses.
+const (
+	pcHeaderMagicGo12  = 0xFFFFFFFB // Go 1.2-1.15, no explicit header struct.
+	pcHeaderMagicGo116 = 0xFFFFFFFA // Go 1.16-1.17, no textStart field.
+	pcHeaderMagicGo118 = 0xFFFFFFF0 // Go 1.18-1.19.
+	pcHeaderMagicGo120 = 0xFFFFFFF1 // Go 1.20+.
+)
+
+// Func is a function recovered from the pclntab.
+type Func struct {
+	// Function name, as recorded by the Go linker (e.g. "main.main").
+	Name string
+	// Entry is the address of the first instruction of the function.
+	Entry bin.Addr
+}
+
+// PCLNTab is the parsed contents of a .gopclntab section: the program
+// counter line table used by the Go runtime for stack traces, recovered here
+// to seed function names and entry addresses.
+type PCLNTab struct {
+	// Funcs are the functions recorded in the pclntab, in the order the
+	// linker emitted them (which is not necessarily address order).
+	Funcs []Func
+}
+
+// pcHeader mirrors (the fields of interest of) runtime.pcHeader for the
+// Go 1.16+ table formats.
+//
+// textStart was only added in Go 1.18; for a Go 1.16/1.17 pclntab it is left
+// zero and entry addresses recorded in the function table are absolute
+// already, rather than being relative to textStart.
+type pcHeader struct {
+	magic          uint32
+	ptrSize        uint8
+	nfunc          uint64
+	nfiles         uint64
+	textStart      uint64
+	funcnameOffset uint64
+	cuOffset       uint64
+	filetabOffset  uint64
+	pctabOffset    uint64
+	// pclnOffset is the offset, from the start of the pclntab, at which the
+	// function table (and the _func records it points into) begins. It is
+	// the last field of the fixed-size header, not the first byte after it:
+	// the header's size depends on both magic (the Go 1.18+ header carries
+	// an extra textStart field absent from Go 1.16/1.17) and ptrSize (every
+	// field from nfunc onward is ptrSize bytes wide, not always 8).
+	pclnOffset uint64
+}
+
+// ParsePCLNTab parses the given .gopclntab section contents, recovering the
+// name and entry address of every function recorded by the Go linker.
+//
+// Only the Go 1.16+ table formats are supported; Go 1.2-1.15 binaries (magic
+// 0xFFFFFFFB) use a different, headerless layout and are reported as a
+// distinct error so callers can fall back to other oracles.
+func ParsePCLNTab(data []byte) (*PCLNTab, error) {
+	if len(data) < 8 {
+		return nil, errors.New("pclntab truncated before magic/header fields")
+	}
+	magic := binary.LittleEndian.Uint32(data)
+	switch magic {
+	case pcHeaderMagicGo12:
+		return nil, errors.New("support for Go 1.2-1.15 pclntab format not yet implemented")
+	case pcHeaderMagicGo116, pcHeaderMagicGo118, pcHeaderMagicGo120:
+		// Supported below.
+	default:
+		return nil, errors.Errorf("unrecognized pclntab magic 0x%08X", magic)
+	}
+	hdr, err := parsePCHeader(magic, data)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tab := &PCLNTab{}
+	// The function table is an array of nfunc+1 (entryOff, funcOff) uint32
+	// pairs (the last entry is a sentinel marking the end of the text
+	// segment), starting at hdr.pclnOffset, not right after the fixed-size
+	// header: the header only describes where to find the various tables, it
+	// is not itself part of the function table's addressing.
+	functab := data[hdr.pclnOffset:]
+	entrySize := 4 // entry offsets are always recorded as uint32 (Go 1.16+).
+	pairSize := entrySize * 2
+	for i := uint64(0); i < hdr.nfunc; i++ {
+		off := i * uint64(pairSize)
+		if off+uint64(pairSize) > uint64(len(functab)) {
+			return nil, errors.Errorf("function table truncated at entry %d", i)
+		}
+		entryOff := binary.LittleEndian.Uint32(functab[off:])
+		funcOff := binary.LittleEndian.Uint32(functab[off+4:])
+		// Go 1.18+ records entry as an offset from textStart; Go 1.16/1.17
+		// (which has no textStart field) records the absolute entry PC.
+		entry := uint64(entryOff)
+		if magic == pcHeaderMagicGo118 || magic == pcHeaderMagicGo120 {
+			entry += hdr.textStart
+		}
+		name, err := readFuncName(data, hdr, hdr.pclnOffset+uint64(funcOff))
+		if err != nil {
+			// A single malformed entry should not abort recovery of the
+			// remaining (likely valid) functions.
+			continue
+		}
+		tab.Funcs = append(tab.Funcs, Func{
+			Name:  name,
+			Entry: bin.Addr(entry),
+		})
+	}
+	return tab, nil
+}
+
+// parsePCHeader decodes the fixed-size pcHeader fields.
+//
+// The header opens with a fixed 8-byte prefix (magic uint32, two pad bytes,
+// minLC, ptrSize), after which every field (nfunc, nfiles, the Go 1.18+-only
+// textStart, funcnameOffset, cuOffset, filetabOffset, pctabOffset and
+// pclnOffset) is ptrSize bytes wide, not always 8: a 32-bit Go binary's
+// pclntab packs these as uint32s.
+func parsePCHeader(magic uint32, data []byte) (*pcHeader, error) {
+	// minLC occupies byte 6; ptrSize (the field of interest here) is the
+	// following byte.
+	const fixedPrefix = 8
+	const ptrSizeOffset = 7
+	if len(data) < fixedPrefix {
+		return nil, errors.New("pclntab truncated before fixed header")
+	}
+	ptrSize := data[ptrSizeOffset]
+	if ptrSize != 4 && ptrSize != 8 {
+		return nil, errors.Errorf("unsupported pclntab pointer size %d", ptrSize)
+	}
+	hasTextStart := magic != pcHeaderMagicGo116
+	numWords := 7
+	if hasTextStart {
+		numWords = 8
+	}
+	hdrSize := fixedPrefix + numWords*int(ptrSize)
+	if len(data) < hdrSize {
+		return nil, errors.New("pclntab truncated before fixed header")
+	}
+	word := 0
+	readWord := func() uint64 {
+		off := fixedPrefix + word*int(ptrSize)
+		word++
+		if ptrSize == 4 {
+			return uint64(binary.LittleEndian.Uint32(data[off:]))
+		}
+		return binary.LittleEndian.Uint64(data[off:])
+	}
+	hdr := &pcHeader{
+		magic:   magic,
+		ptrSize: ptrSize,
+		nfunc:   readWord(),
+		nfiles:  readWord(),
+	}
+	if hasTextStart {
+		hdr.textStart = readWord()
+	}
+	hdr.funcnameOffset = readWord()
+	hdr.cuOffset = readWord()
+	hdr.filetabOffset = readWord()
+	hdr.pctabOffset = readWord()
+	hdr.pclnOffset = readWord()
+	return hdr, nil
+}
+
+// readFuncName reads the NUL-terminated function name at the given offset
+// into the funcnametab, as referenced (indirectly, via the _func struct's
+// nameOff field) by a pclntab function table entry.
+//
+// funcRecordOff is the absolute offset into data (i.e. already based at
+// hdr.pclnOffset) of the `_func` record whose first field (after the fixed
+// entry offset already read from the function table) is a uint32 nameOff
+// relative to funcnameOffset.
+func readFuncName(data []byte, hdr *pcHeader, funcRecordOff uint64) (string, error) {
+	base := hdr.funcnameOffset
+	// _func.entryOff (uint32) is followed by _func.nameOff (uint32).
+	const nameOffFieldOffset = 4
+	pos := funcRecordOff + nameOffFieldOffset
+	if pos+4 > uint64(len(data)) {
+		return "", errors.New("_func record truncated before nameOff field")
+	}
+	nameOff := binary.LittleEndian.Uint32(data[pos:])
+	start := base + uint64(nameOff)
+	if start >= uint64(len(data)) {
+		return "", errors.New("function name offset out of bounds")
+	}
+	end := start
+	for end < uint64(len(data)) && data[end] != 0 {
+		end++
+	}
+	return string(data[start:end]), nil
+}
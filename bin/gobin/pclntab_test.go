@@ -0,0 +1,164 @@
+package gobin
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/mewmew/x/bin"
+)
+
+// putWord writes v to buf at off, encoded as ptrSize (4 or 8) little-endian
+// bytes, mirroring parsePCHeader's per-field width.
+func putWord(buf []byte, off int, ptrSize uint8, v uint64) {
+	if ptrSize == 4 {
+		binary.LittleEndian.PutUint32(buf[off:], uint32(v))
+		return
+	}
+	binary.LittleEndian.PutUint64(buf[off:], v)
+}
+
+// putHeader writes a pclntab fixed-size header (and, for Go 1.18+, the
+// textStart field) to buf, gated on magic the same way parsePCHeader reads
+// it, with every field after the fixed 8-byte prefix encoded as ptrSize
+// bytes. It returns the header size in bytes.
+func putHeader(buf []byte, magic uint32, ptrSize uint8, nfunc, nfiles, textStart, funcnameOffset, cuOffset, filetabOffset, pctabOffset, pclnOffset uint64) int {
+	binary.LittleEndian.PutUint32(buf[0:], magic)
+	buf[7] = ptrSize // minLC is buf[6], ptrSize is buf[7].
+	word := 8
+	put := func(v uint64) {
+		putWord(buf, word, ptrSize, v)
+		word += int(ptrSize)
+	}
+	put(nfunc)
+	put(nfiles)
+	if magic != pcHeaderMagicGo116 {
+		put(textStart)
+	}
+	put(funcnameOffset)
+	put(cuOffset)
+	put(filetabOffset)
+	put(pctabOffset)
+	put(pclnOffset)
+	return word
+}
+
+// buildPCLNTab builds a pclntab with one recorded function, named name,
+// entered at entryOff (relative to textStart for Go 1.18+, absolute for Go
+// 1.16/1.17). The layout deliberately separates the function table (at
+// pclnOffset, past an unrelated gap simulating the filetab/pctab the header
+// also points into) from the start of the header, so a fixture bug that
+// conflates "right after the header" with pclnOffset would be caught.
+func buildPCLNTab(magic uint32, ptrSize uint8, entryOff uint32, textStart uint64, name string) []byte {
+	numWords := 7
+	if magic != pcHeaderMagicGo116 {
+		numWords = 8
+	}
+	hdrSize := 8 + numWords*int(ptrSize)
+	const gapSize = 16 // stands in for tables the header points into that precede the function table.
+	pclnOffset := uint64(hdrSize + gapSize)
+	functabSize := 2 * 2 * 4 // 2 (entryOff, funcOff) pairs, 2 uint32 fields each.
+	nameOff := uint32(8)     // _func.entryOff (4 bytes) + _func.nameOff (4 bytes) precede the name.
+	funcnametabSize := int(nameOff) + len(name) + 1
+	data := make([]byte, int(pclnOffset)+functabSize+funcnametabSize)
+	// _func record sits right at the start of the funcnametab, so its offset
+	// relative to funcnameOffset is 0; the function table stores it relative
+	// to pclnOffset instead (as functabSize). Its nameOff field is relative
+	// to funcnameOffset.
+	funcnameOffset := pclnOffset + uint64(functabSize)
+	putHeader(data, magic, ptrSize, 1, 0, textStart, funcnameOffset, 0, 0, 0, pclnOffset)
+	functab := data[pclnOffset:]
+	binary.LittleEndian.PutUint32(functab[0:], entryOff)
+	binary.LittleEndian.PutUint32(functab[4:], uint32(functabSize)) // funcOff, relative to pclnOffset.
+	funcRecord := data[pclnOffset+uint64(functabSize):]
+	binary.LittleEndian.PutUint32(funcRecord[0:], entryOff) // _func.entryOff, unread by readFuncName.
+	binary.LittleEndian.PutUint32(funcRecord[4:], nameOff)
+	copy(funcRecord[nameOff:], name)
+	return data
+}
+
+func TestParsePCLNTab(t *testing.T) {
+	golden := []struct {
+		name      string
+		magic     uint32
+		ptrSize   uint8
+		entryOff  uint32
+		textStart uint64
+		wantEntry bin.Addr
+	}{
+		{
+			// Go 1.16/1.17 has no textStart field; the function table
+			// records the absolute entry PC directly.
+			name:      "go1.16",
+			magic:     pcHeaderMagicGo116,
+			ptrSize:   8,
+			entryOff:  0x1000,
+			textStart: 0, // not present in this header generation.
+			wantEntry: 0x1000,
+		},
+		{
+			// Go 1.18+ records entry addresses relative to textStart.
+			name:      "go1.18",
+			magic:     pcHeaderMagicGo118,
+			ptrSize:   8,
+			entryOff:  0x40,
+			textStart: 0x400000,
+			wantEntry: 0x400040,
+		},
+		{
+			name:      "go1.20",
+			magic:     pcHeaderMagicGo120,
+			ptrSize:   8,
+			entryOff:  0x80,
+			textStart: 0x401000,
+			wantEntry: 0x401080,
+		},
+		{
+			// A 32-bit Go binary packs every header field after the fixed
+			// prefix as a uint32, not a uint64.
+			name:      "go1.20-32bit",
+			magic:     pcHeaderMagicGo120,
+			ptrSize:   4,
+			entryOff:  0x80,
+			textStart: 0x8048000,
+			wantEntry: 0x8048080,
+		},
+	}
+	for _, g := range golden {
+		t.Run(g.name, func(t *testing.T) {
+			data := buildPCLNTab(g.magic, g.ptrSize, g.entryOff, g.textStart, "main.main")
+			tab, err := ParsePCLNTab(data)
+			if err != nil {
+				t.Fatalf("ParsePCLNTab: %v", err)
+			}
+			if len(tab.Funcs) != 1 {
+				t.Fatalf("got %d funcs, want 1", len(tab.Funcs))
+			}
+			got := tab.Funcs[0]
+			if got.Name != "main.main" {
+				t.Errorf("Name: got %q, want %q", got.Name, "main.main")
+			}
+			if got.Entry != g.wantEntry {
+				t.Errorf("Entry: got %v, want %v", got.Entry, g.wantEntry)
+			}
+		})
+	}
+}
+
+// TestParsePCLNTabGo12Rejected verifies that the headerless Go 1.2-1.15
+// layout is reported as unimplemented rather than parsed with the wrong
+// (Go 1.16+) field offsets.
+func TestParsePCLNTabGo12Rejected(t *testing.T) {
+	data := make([]byte, 64)
+	binary.LittleEndian.PutUint32(data, pcHeaderMagicGo12)
+	if _, err := ParsePCLNTab(data); err == nil {
+		t.Fatal("ParsePCLNTab: expected error for Go 1.2-1.15 magic, got nil")
+	}
+}
+
+func TestParsePCLNTabUnrecognizedMagic(t *testing.T) {
+	data := make([]byte, 64)
+	binary.LittleEndian.PutUint32(data, 0xDEADBEEF)
+	if _, err := ParsePCLNTab(data); err == nil {
+		t.Fatal("ParsePCLNTab: expected error for unrecognized magic, got nil")
+	}
+}
@@ -0,0 +1,216 @@
+// Package macho provides access to Mach-O binaries, exposed through the
+// bin.File interface.
+package macho
+
+import (
+	"debug/macho"
+	"encoding/binary"
+
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+)
+
+// Mach-O load command and section flag values not exported by debug/macho.
+const (
+	// lcMain is LC_MAIN, the entry_point_command recorded by modern
+	// (dyld-based) binaries.
+	lcMain = 0x80000028
+	// lcUnixThread is LC_UNIXTHREAD, the legacy thread_command used as the
+	// entry point mechanism by binaries predating LC_MAIN.
+	lcUnixThread = 0x5
+	// x86ThreadState64 is the x86_THREAD_STATE64 flavor of an LC_UNIXTHREAD
+	// register dump.
+	x86ThreadState64 = 4
+
+	// sAttrPureInstructions marks a section as containing only instructions,
+	// S_ATTR_PURE_INSTRUCTIONS in mach-o/loader.h.
+	sAttrPureInstructions = 0x80000000
+	// sAttrSomeInstructions marks a section as containing some instructions,
+	// S_ATTR_SOME_INSTRUCTIONS in mach-o/loader.h.
+	sAttrSomeInstructions = 0x00000400
+)
+
+// File is a Mach-O binary executable.
+type File struct {
+	// Underlying Mach-O file.
+	file *macho.File
+	// Native address width in number of bits (32 or 64).
+	bitness int
+}
+
+// machineOf converts a Mach-O cputype constant to a bin.Machine.
+func machineOf(cpu macho.Cpu) bin.Machine {
+	switch cpu {
+	case macho.Cpu386:
+		return bin.MachineX86
+	case macho.CpuAmd64:
+		return bin.MachineX86_64
+	case macho.CpuArm:
+		return bin.MachineARM
+	case macho.CpuArm64:
+		return bin.MachineARM64
+	case macho.CpuPpc64:
+		return bin.MachinePPC64
+	default:
+		return bin.MachineUnknown
+	}
+}
+
+// Open opens the Mach-O binary executable at the given path.
+func Open(path string) (*File, error) {
+	file, err := macho.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &File{file: file}
+	switch file.Magic {
+	case macho.Magic32:
+		f.bitness = 32
+	case macho.Magic64:
+		f.bitness = 64
+	default:
+		return nil, errors.Errorf("support for Mach-O magic 0x%X not yet implemented", file.Magic)
+	}
+	return f, nil
+}
+
+// Bitness returns the native address width of the executable, in number of
+// bits (32 or 64).
+func (f *File) Bitness() int {
+	return f.bitness
+}
+
+// Machine returns the instruction set architecture the executable targets.
+func (f *File) Machine() bin.Machine {
+	return machineOf(f.file.Cpu)
+}
+
+// ImageBase returns the preferred load address of the executable image.
+func (f *File) ImageBase() bin.Addr {
+	for _, load := range f.file.Loads {
+		if seg, ok := load.(*macho.Segment); ok && seg.Name == "__TEXT" {
+			return bin.Addr(seg.Addr)
+		}
+	}
+	return 0
+}
+
+// Entry returns the address of the entry point of the executable.
+//
+// debug/macho does not model LC_MAIN or LC_UNIXTHREAD as distinct Load
+// types (both decode as opaque macho.LoadBytes), so the entry point is
+// recovered by matching the raw load command bytes directly.
+func (f *File) Entry() bin.Addr {
+	for _, load := range f.file.Loads {
+		raw, ok := load.(macho.LoadBytes)
+		if !ok {
+			continue
+		}
+		data := raw.Raw()
+		if len(data) < 8 {
+			continue
+		}
+		switch f.file.ByteOrder.Uint32(data[0:4]) {
+		case lcMain:
+			if len(data) < 16 {
+				continue
+			}
+			entryOff := f.file.ByteOrder.Uint64(data[8:16])
+			return f.ImageBase() + bin.Addr(entryOff)
+		case lcUnixThread:
+			if entry, ok := unixThreadEntry(f.file.ByteOrder, data); ok {
+				return bin.Addr(entry)
+			}
+		}
+	}
+	return 0
+}
+
+// unixThreadEntry extracts the initial instruction pointer from a legacy
+// LC_UNIXTHREAD load command's x86_THREAD_STATE64 register dump, used as
+// the entry point by binaries predating LC_MAIN.
+func unixThreadEntry(bo binary.ByteOrder, data []byte) (uint64, bool) {
+	// Layout: cmd, cmdsize (8 bytes, already consumed by the caller's length
+	// check), flavor, count (8 bytes), then the flavor-specific register
+	// state. __x86_thread_state64's rip field is the 17th uint64 (index 16)
+	// of that state.
+	const (
+		stateOffset = 16
+		ripIndex    = 16
+	)
+	if len(data) < stateOffset {
+		return 0, false
+	}
+	flavor := bo.Uint32(data[8:12])
+	if flavor != x86ThreadState64 {
+		return 0, false
+	}
+	ripOffset := stateOffset + ripIndex*8
+	if len(data) < ripOffset+8 {
+		return 0, false
+	}
+	return bo.Uint64(data[ripOffset : ripOffset+8]), true
+}
+
+// Sections returns the sections of the executable.
+func (f *File) Sections() ([]bin.Section, error) {
+	var sects []bin.Section
+	for _, sect := range f.file.Sections {
+		data, err := sect.Data()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		perm := bin.PermR
+		if sect.Flags&sAttrPureInstructions != 0 || sect.Flags&sAttrSomeInstructions != 0 {
+			perm |= bin.PermX
+		}
+		sects = append(sects, bin.Section{
+			Name: sect.Name,
+			Addr: bin.Addr(sect.Addr),
+			Data: data,
+			Perm: perm,
+		})
+	}
+	return sects, nil
+}
+
+// Symbols returns the symbol table of the executable, or nil if the
+// executable contains no symbol information.
+func (f *File) Symbols() []bin.Symbol {
+	if f.file.Symtab == nil {
+		return nil
+	}
+	var syms []bin.Symbol
+	for _, sym := range f.file.Symtab.Syms {
+		syms = append(syms, bin.Symbol{
+			Name: sym.Name,
+			Addr: bin.Addr(sym.Value),
+		})
+	}
+	return syms
+}
+
+// Imports returns the symbols imported by the executable.
+func (f *File) Imports() []bin.Symbol {
+	names, err := f.file.ImportedSymbols()
+	if err != nil {
+		return nil
+	}
+	var syms []bin.Symbol
+	for _, name := range names {
+		syms = append(syms, bin.Symbol{Name: name})
+	}
+	return syms
+}
+
+// Exports returns the symbols exported by the executable.
+func (f *File) Exports() []bin.Symbol {
+	// TODO: recover exported symbols from the export trie once dyld info
+	// parsing is in place.
+	return nil
+}
+
+// Close closes the executable.
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,39 @@
+// Package open sniffs the file magic of a binary executable and dispatches
+// to the appropriate bin sub-package (bin/pe, bin/elf, bin/macho).
+//
+// It lives in its own leaf package, separate from bin, because bin/pe,
+// bin/elf and bin/macho each import bin for the File/Addr/Section/Symbol
+// types; a dispatcher living inside bin itself would import its own
+// sub-packages and form an import cycle.
+package open
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/bin/elf"
+	"github.com/mewmew/x/bin/macho"
+	"github.com/mewmew/x/bin/pe"
+	"github.com/pkg/errors"
+)
+
+// Open opens the binary executable at the given path, sniffing the file
+// magic to determine whether it is a PE, ELF or Mach-O image.
+func Open(path string) (bin.File, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	switch {
+	case bytes.HasPrefix(buf, []byte("MZ")):
+		return pe.Open(path)
+	case bytes.HasPrefix(buf, []byte("\x7fELF")):
+		return elf.Open(path)
+	case bytes.HasPrefix(buf, []byte("\xFE\xED\xFA\xCE")), bytes.HasPrefix(buf, []byte("\xFE\xED\xFA\xCF")),
+		bytes.HasPrefix(buf, []byte("\xCE\xFA\xED\xFE")), bytes.HasPrefix(buf, []byte("\xCF\xFA\xED\xFE")):
+		return macho.Open(path)
+	default:
+		return nil, errors.Errorf("unable to locate binary executable format of %q; unrecognized file magic", path)
+	}
+}
@@ -0,0 +1,142 @@
+// Package pe provides access to PE (Portable Executable) binaries, exposed
+// through the bin.File interface.
+package pe
+
+import (
+	"debug/pe"
+
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+)
+
+// codeMask is the IMAGE_SCN_CNT_CODE section characteristics flag.
+const codeMask = 0x00000020
+
+// File is a PE binary executable.
+type File struct {
+	// Underlying PE file.
+	file *pe.File
+	// Preferred load address of the executable image.
+	imageBase bin.Addr
+	// Address of the entry point of the executable.
+	entry bin.Addr
+	// Native address width in number of bits (32 or 64).
+	bitness int
+	// Instruction set architecture targeted by the executable.
+	machine bin.Machine
+}
+
+// Open opens the PE binary executable at the given path.
+func Open(path string) (*File, error) {
+	file, err := pe.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := &File{file: file}
+	switch optHdr := file.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		f.imageBase = bin.Addr(optHdr.ImageBase)
+		f.entry = f.imageBase + bin.Addr(optHdr.AddressOfEntryPoint)
+		f.bitness = 32
+	case *pe.OptionalHeader64:
+		f.imageBase = bin.Addr(optHdr.ImageBase)
+		f.entry = f.imageBase + bin.Addr(optHdr.AddressOfEntryPoint)
+		f.bitness = 64
+	default:
+		return nil, errors.Errorf("support for optional header type %T not yet implemented", file.OptionalHeader)
+	}
+	f.machine = machineOf(file.Machine)
+	return f, nil
+}
+
+// machineOf converts a PE IMAGE_FILE_MACHINE_* constant to a bin.Machine.
+func machineOf(m uint16) bin.Machine {
+	switch m {
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return bin.MachineX86
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return bin.MachineX86_64
+	case pe.IMAGE_FILE_MACHINE_ARM, pe.IMAGE_FILE_MACHINE_ARMNT:
+		return bin.MachineARM
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return bin.MachineARM64
+	default:
+		return bin.MachineUnknown
+	}
+}
+
+// Bitness returns the native address width of the executable, in number of
+// bits (32 or 64).
+func (f *File) Bitness() int {
+	return f.bitness
+}
+
+// Machine returns the instruction set architecture the executable targets.
+func (f *File) Machine() bin.Machine {
+	return f.machine
+}
+
+// ImageBase returns the preferred load address of the executable image.
+func (f *File) ImageBase() bin.Addr {
+	return f.imageBase
+}
+
+// Entry returns the address of the entry point of the executable.
+func (f *File) Entry() bin.Addr {
+	return f.entry
+}
+
+// Sections returns the sections of the executable.
+func (f *File) Sections() ([]bin.Section, error) {
+	var sects []bin.Section
+	for _, sect := range f.file.Sections {
+		data, err := sect.Data()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		perm := bin.PermR
+		if sect.Characteristics&0x80000000 != 0 { // IMAGE_SCN_MEM_WRITE
+			perm |= bin.PermW
+		}
+		if sect.Characteristics&codeMask != 0 {
+			perm |= bin.PermX
+		}
+		sects = append(sects, bin.Section{
+			Name: sect.Name,
+			Addr: f.imageBase + bin.Addr(sect.VirtualAddress),
+			Data: data,
+			Perm: perm,
+		})
+	}
+	return sects, nil
+}
+
+// Symbols returns the symbol table of the executable, or nil if the
+// executable contains no symbol information.
+func (f *File) Symbols() []bin.Symbol {
+	var syms []bin.Symbol
+	for _, sym := range f.file.Symbols {
+		syms = append(syms, bin.Symbol{
+			Name: sym.Name,
+			Addr: f.imageBase + bin.Addr(sym.Value),
+		})
+	}
+	return syms
+}
+
+// Imports returns the symbols imported by the executable.
+func (f *File) Imports() []bin.Symbol {
+	// TODO: parse the import directory (.idata) to recover imported symbols.
+	return nil
+}
+
+// Exports returns the symbols exported by the executable.
+func (f *File) Exports() []bin.Symbol {
+	// TODO: parse the export directory (.edata) to recover exported symbols.
+	return nil
+}
+
+// Close closes the executable.
+func (f *File) Close() error {
+	return f.file.Close()
+}
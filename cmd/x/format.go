@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86"
+	"github.com/mewmew/x/disasm/x86/discover"
+	"github.com/pkg/errors"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// writeFuncs writes the decoded functions and basic blocks in the given
+// output format ("text", "json" or "dot").
+func (l *lifter) writeFuncs(funcs []*x86.Function) error {
+	switch l.format {
+	case "", "text":
+		return l.writeFuncsText(funcs)
+	case "json":
+		return l.writeFuncsJSON(funcs)
+	case "dot":
+		return l.writeFuncsDot(funcs)
+	default:
+		return errors.Errorf("support for output format %q not yet implemented", l.format)
+	}
+}
+
+// writeFuncsText prints the human-readable disassembly of funcs to
+// standard output.
+func (l *lifter) writeFuncsText(funcs []*x86.Function) error {
+	for _, f := range funcs {
+		fmt.Println(f)
+	}
+	return nil
+}
+
+// jsonFunc is the JSON representation of a decoded function.
+type jsonFunc struct {
+	Addr bin.Addr `json:"addr"`
+	// Name is the function's recovered Go name (e.g. "main.main"), or the
+	// generic "func_%08X" placeholder if unknown.
+	Name   string      `json:"name"`
+	Blocks []jsonBlock `json:"blocks"`
+}
+
+// jsonBlock is the JSON representation of a decoded basic block.
+type jsonBlock struct {
+	Addr  bin.Addr   `json:"addr"`
+	Insts []jsonInst `json:"insts"`
+	Succs []bin.Addr `json:"succs"`
+}
+
+// jsonInst is the JSON representation of a decoded instruction.
+type jsonInst struct {
+	Addr bin.Addr `json:"addr"`
+	Op   string   `json:"op"`
+	Args []string `json:"args"`
+}
+
+// writeFuncsJSON writes a JSON representation of funcs, their basic blocks,
+// instructions and successor edges to "<binPath>.json".
+func (l *lifter) writeFuncsJSON(funcs []*x86.Function) error {
+	var jsonFuncs []jsonFunc
+	for _, f := range funcs {
+		jsonFuncs = append(jsonFuncs, toJSONFunc(f))
+	}
+	buf, err := json.MarshalIndent(jsonFuncs, "", "\t")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	jsonPath := l.binPath + ".json"
+	dbg.Printf("writing JSON analysis to %q", jsonPath)
+	if err := ioutil.WriteFile(jsonPath, buf, 0644); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// toJSONFunc converts f to its JSON representation.
+func toJSONFunc(f *x86.Function) jsonFunc {
+	var keys bin.Addrs
+	for key := range f.Blocks {
+		keys = append(keys, key)
+	}
+	sort.Sort(keys)
+	jf := jsonFunc{Addr: f.Entry, Name: f.Name()}
+	for _, key := range keys {
+		jf.Blocks = append(jf.Blocks, toJSONBlock(f.Blocks[key]))
+	}
+	return jf
+}
+
+// toJSONBlock converts block to its JSON representation.
+func toJSONBlock(block *x86.BasicBlock) jsonBlock {
+	jb := jsonBlock{Addr: block.Entry()}
+	for _, inst := range block.Insts() {
+		var args []string
+		for _, arg := range inst.Args {
+			if arg == nil {
+				break
+			}
+			args = append(args, arg.String())
+		}
+		jb.Insts = append(jb.Insts, jsonInst{
+			Addr: inst.Addr(),
+			Op:   inst.Op.String(),
+			Args: args,
+		})
+	}
+	jb.Succs = blockSuccs(block)
+	return jb
+}
+
+// blockSuccs returns the successor addresses of block, derived from its
+// last instruction's terminator semantics.
+func blockSuccs(block *x86.BasicBlock) []bin.Addr {
+	insts := block.Insts()
+	last := insts[len(insts)-1]
+	next := last.Addr() + bin.Addr(last.Len)
+	switch {
+	case last.Op == x86asm.RET:
+		return nil
+	case last.Op == x86asm.JMP:
+		if target, ok := discover.RelTarget(last.Addr(), last.Inst); ok {
+			return []bin.Addr{target}
+		}
+		return nil
+	case discover.IsCondJump(last.Op):
+		var succs []bin.Addr
+		if target, ok := discover.RelTarget(last.Addr(), last.Inst); ok {
+			succs = append(succs, target)
+		}
+		return append(succs, next)
+	default:
+		return []bin.Addr{next}
+	}
+}
+
+// writeFuncsDot writes a Graphviz CFG of each function in funcs to
+// "<binPath>_func_<addr>.dot".
+func (l *lifter) writeFuncsDot(funcs []*x86.Function) error {
+	for _, f := range funcs {
+		buf := &bytes.Buffer{}
+		fmt.Fprintf(buf, "digraph func_%08X {\n", uint32(f.Entry))
+		fmt.Fprintf(buf, "\tlabel=%q;\n", f.Name())
+		var keys bin.Addrs
+		for key := range f.Blocks {
+			keys = append(keys, key)
+		}
+		sort.Sort(keys)
+		for _, key := range keys {
+			block := f.Blocks[key]
+			fmt.Fprintf(buf, "\tblock_%08X [shape=box, label=%q];\n", uint32(key), block.String())
+			for _, succ := range blockSuccs(block) {
+				fmt.Fprintf(buf, "\tblock_%08X -> block_%08X;\n", uint32(key), uint32(succ))
+			}
+		}
+		buf.WriteString("}\n")
+		dotPath := fmt.Sprintf("%s_func_%08X.dot", l.binPath, uint32(f.Entry))
+		dbg.Printf("writing Graphviz CFG to %q", dotPath)
+		if err := ioutil.WriteFile(dotPath, buf.Bytes(), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
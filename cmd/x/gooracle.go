@@ -0,0 +1,75 @@
+package main
+
+import (
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/bin/gobin"
+	"github.com/pkg/errors"
+)
+
+// goOracle is an Oracle backed by bin/gobin's recovery of a Go-compiled
+// binary's pclntab, providing real Go function names and entry addresses so
+// that lifting a Go program does not require a hand-authored funcs.json.
+//
+// It does not scan sections or parse the pclntab itself; that is entirely
+// bin/gobin's responsibility (newLifter obtains Go names only through this
+// oracle), so there is exactly one place in the tree that knows the
+// pclntab section names and header layout.
+//
+// File/line annotations are not yet recovered; bin/gobin's PCLNTab only
+// decodes the function name/entry table so far (TODO, see bin/gobin).
+type goOracle struct {
+	funcAddrs bin.Addrs
+	// names maps from function entry address to its Go-linker-recorded
+	// name (e.g. "main.main"), used to name functions in output instead of
+	// the generic "func_%08X" placeholder.
+	names map[bin.Addr]string
+	// buildInfo is the module path/version/toolchain recorded by the Go
+	// linker, or nil if the binary's build info blob was absent or
+	// unparseable.
+	buildInfo *gobin.BuildInfo
+}
+
+// newGoOracle recovers Go-specific metadata from file via bin/gobin. ok
+// reports whether file is a Go binary; a binary that is not Go (or strips
+// its pclntab section) is not an error.
+func newGoOracle(file bin.File) (o *goOracle, ok bool, err error) {
+	isGo, err := gobin.IsGoBinary(file)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	if !isGo {
+		return nil, false, nil
+	}
+	info, err := gobin.Parse(file)
+	if err != nil {
+		return nil, true, errors.WithStack(err)
+	}
+	o = &goOracle{names: make(map[bin.Addr]string), buildInfo: info.BuildInfo}
+	if info.PCLNTab != nil {
+		for _, fn := range info.PCLNTab.Funcs {
+			o.funcAddrs = append(o.funcAddrs, fn.Entry)
+			o.names[fn.Entry] = fn.Name
+		}
+	}
+	return o, true, nil
+}
+
+func (o *goOracle) FuncAddrs() bin.Addrs { return o.funcAddrs }
+
+// BlockAddrs is always empty; the pclntab records function boundaries, not
+// basic block boundaries.
+func (o *goOracle) BlockAddrs() bin.Addrs { return nil }
+
+// CallingConv is not yet implemented.
+func (o *goOracle) CallingConv(addr bin.Addr) string { return "" }
+
+// FuncSig is not yet implemented.
+//
+// TODO: recover argument/return sizes from the pclntab's funcdata once
+// bin/gobin exposes them.
+func (o *goOracle) FuncSig(addr bin.Addr) string { return "" }
+
+// TypeOf is not applicable to a pclntab-backed oracle.
+func (o *goOracle) TypeOf(addr bin.Addr) string { return "" }
+
+var _ Oracle = (*goOracle)(nil)
@@ -1,8 +1,12 @@
 package main
 
 import (
+	"encoding/json"
+	"os"
+
 	"github.com/mewkiz/pkg/jsonutil"
 	"github.com/mewkiz/pkg/osutil"
+	"github.com/pkg/errors"
 )
 
 // parseJSON parses the given JSON file and stores the result into v.
@@ -14,3 +18,23 @@ func parseJSON(jsonPath string, v interface{}) error {
 	dbg.Printf("parseJson(jsonPath = %q, v = %T)", jsonPath, v)
 	return jsonutil.ParseFile(jsonPath, v)
 }
+
+// decodeOptionalJSON decodes the named JSON file into v, if present; a
+// missing file is not an error, since JSON oracles are an optional
+// augmentation of the addresses found by recursive-descent discovery.
+func decodeOptionalJSON(jsonPath string, v interface{}) error {
+	dbg.Printf("decodeOptionalJSON(jsonPath = %q)", jsonPath)
+	f, err := os.Open(jsonPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	if err := dec.Decode(v); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -1,6 +1,18 @@
 package main
 
-import "github.com/llir/llvm/ir"
+import (
+	"fmt"
+	"sort"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/types"
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86"
+	"github.com/mewmew/x/disasm/x86/discover"
+	"github.com/mewmew/x/disasm/x86/sem"
+	"github.com/pkg/errors"
+	"golang.org/x/arch/x86/x86asm"
+)
 
 // funcLifter is a lifter for a given LLVM IR function.
 type funcLifter struct {
@@ -8,7 +20,119 @@ type funcLifter struct {
 	l *lifter
 
 	// LLVM IR function being lifted.
-	f *ir.Function
-	// Current basic block being lifted.
-	cur *ir.BasicBlock
+	f *ir.Func
+	// Register/flag state of the function, backed by allocas in its entry
+	// block.
+	state *sem.State
+	// Maps from x86 basic block address to the corresponding LLVM IR basic
+	// block, pre-populated so that a forward branch always has a block to
+	// target regardless of lifting order.
+	blocks map[bin.Addr]*ir.Block
+}
+
+// newFuncLifter returns a new function lifter, used to lift the given LLVM IR
+// function.
+func newFuncLifter(l *lifter, f *ir.Func) *funcLifter {
+	return &funcLifter{
+		l:      l,
+		f:      f,
+		blocks: make(map[bin.Addr]*ir.Block),
+	}
+}
+
+// liftFunc lifts the given x86 function to an equivalent LLVM IR function.
+func (fl *funcLifter) liftFunc(asmFunc *x86.Function) error {
+	var keys bin.Addrs
+	for key := range asmFunc.Blocks {
+		keys = append(keys, key)
+	}
+	sort.Sort(keys)
+	// Create the LLVM IR basic blocks up front, before lifting any
+	// instructions, so that a forward branch always resolves to an existing
+	// block.
+	for _, key := range keys {
+		blockName := fmt.Sprintf("block_%08X", uint32(key))
+		llBlock := ir.NewBlock(blockName)
+		fl.blocks[key] = llBlock
+		fl.f.Blocks = append(fl.f.Blocks, llBlock)
+	}
+	fl.state = sem.NewState(fl.f.Blocks[0], fl.l.file.Bitness())
+	for _, key := range keys {
+		asmBlock := asmFunc.Blocks[key]
+		if err := fl.liftBlock(asmBlock); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// liftBlock lifts the given x86 basic block to its pre-created LLVM IR basic
+// block, translating every instruction but the last through sem.Translator
+// and the last (a terminator, or the last instruction of a chunk forcibly
+// split without ending on one) into the LLVM IR block's terminator.
+func (fl *funcLifter) liftBlock(asmBlock *x86.BasicBlock) error {
+	llBlock := fl.blocks[asmBlock.Entry()]
+	tr := sem.NewTranslator(fl.state, llBlock)
+	insts := asmBlock.Insts()
+	last := insts[len(insts)-1]
+	for _, asmInst := range insts[:len(insts)-1] {
+		if err := tr.Translate(asmInst.Addr(), asmInst.Inst); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	term, err := fl.translateTerm(tr, last)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	llBlock.Term = term
+	return nil
+}
+
+// translateTerm translates the last instruction of a basic block into the
+// LLVM IR terminator of the corresponding LLVM IR basic block, mirroring the
+// successor logic of format.go's blockSuccs: RET ends the function, JMP and
+// conditional jumps become unconditional and conditional branches, and any
+// other opcode (a basic block forcibly split at a non-continuous chunk
+// boundary, not on a real terminator) is translated as an ordinary
+// instruction followed by a fallthrough branch.
+func (fl *funcLifter) translateTerm(tr *sem.Translator, last *x86.Instruction) (ir.Terminator, error) {
+	next := last.Addr() + bin.Addr(last.Len)
+	switch {
+	case last.Op == x86asm.RET:
+		if types.IsVoid(fl.f.Sig.RetType) {
+			return ir.NewRet(nil), nil
+		}
+		ret, err := tr.LoadReg(x86asm.EAX)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return ir.NewRet(ret), nil
+	case last.Op == x86asm.JMP:
+		target, ok := discover.RelTarget(last.Addr(), last.Inst)
+		if !ok {
+			return nil, errors.Errorf("support for indirect JMP not yet implemented; unable to translate instruction at %v", last.Addr())
+		}
+		return ir.NewBr(fl.targetBlock(target)), nil
+	case discover.IsCondJump(last.Op):
+		cond, err := tr.Cond(last.Op)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		target, ok := discover.RelTarget(last.Addr(), last.Inst)
+		if !ok {
+			return nil, errors.Errorf("support for indirect conditional jump not yet implemented; unable to translate instruction at %v", last.Addr())
+		}
+		return ir.NewCondBr(cond, fl.targetBlock(target), fl.targetBlock(next)), nil
+	default:
+		if err := tr.Translate(last.Addr(), last.Inst); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return ir.NewBr(fl.targetBlock(next)), nil
+	}
+}
+
+// targetBlock returns the LLVM IR basic block lifted from the x86 basic
+// block at addr.
+func (fl *funcLifter) targetBlock(addr bin.Addr) *ir.Block {
+	return fl.blocks[addr]
 }
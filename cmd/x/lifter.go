@@ -1,93 +1,169 @@
 package main
 
 import (
-	"debug/pe"
-	"sort"
+	"io/ioutil"
 
 	"github.com/llir/llvm/ir"
+	"github.com/mewmew/x/arch"
+	_ "github.com/mewmew/x/arch/arm"   // register arch.Select backend
+	_ "github.com/mewmew/x/arch/arm64" // register arch.Select backend
+	_ "github.com/mewmew/x/arch/ppc"   // register arch.Select backend
+	x86Backend "github.com/mewmew/x/arch/x86"
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/bin/gobin"
+	"github.com/mewmew/x/bin/open"
+	"github.com/mewmew/x/disasm/x86"
+	"github.com/mewmew/x/disasm/x86/discover"
 	"github.com/pkg/errors"
 )
 
 // lifter is a binary executable to LLVM IR lifter.
 type lifter struct {
-	// x86 disassembler.
-
 	// Binary executable path.
 	binPath string
-	// Function addresses.
-	funcAddrs Addrs
-	// Basic block addresses.
-	blockAddrs Addrs
-	// Maps from basic block address to the set of non-continuous functions that
-	// basic block belongs to.
-	chunks map[Addr]map[Addr]bool
+	// Output format of the decoded analysis ("text", "json" or "dot").
+	format string
+	// Parsed binary executable (PE, ELF or Mach-O).
+	file bin.File
+	// Maps from basic block address to the set of non-continuous functions
+	// that basic block belongs to.
+	chunks map[bin.Addr]map[bin.Addr]bool
 
-	// x86 functions.
-	asmFuncs []*Function
+	// x86 lifter.
+	x86 *x86.Lifter
 
 	// LLVM IR lifter.
 
 	// Maps from function address to LLVM IR function.
-	funcs map[Addr]*ir.Function
+	funcs map[bin.Addr]*ir.Func
+	// buildInfo is the Go module path/version/toolchain recovered from the
+	// binary's build info blob, or nil for a non-Go binary (or one whose
+	// build info could not be recovered).
+	buildInfo *gobin.BuildInfo
 }
 
-// newLifter returns a new lifter based on the given binary executable path.
-func newLifter(binPath string) (*lifter, error) {
+// newLifter returns a new lifter for the binary executable at the given
+// path, consulting the given external oracles (in addition to funcs.json,
+// blocks.json, recursive-descent discovery and, for Go binaries, the
+// pclntab) for function and basic block addresses.
+func newLifter(binPath string, oracles ...Oracle) (*lifter, error) {
+	file, err := open.Open(binPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Select the architecture back-end for the executable's machine type.
+	// The x86 front-end below is the only one wired up to actually decode
+	// and lift instructions; this rejects other architectures up front with
+	// a clear error instead of silently misdecoding them as x86.
+	backend, err := arch.Select(file)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, ok := backend.(*x86Backend.Backend); !ok {
+		return nil, errors.Errorf("lifting machine architecture %v not yet implemented", file.Machine())
+	}
 	l := &lifter{
 		binPath: binPath,
-		funcs:   make(map[Addr]*ir.Function),
+		file:    file,
+		x86:     x86.NewLifter(file.Bitness()),
+		funcs:   make(map[bin.Addr]*ir.Func),
 	}
-	// Parse function addresses.
-	if err := parseJSON("funcs.json", &l.funcAddrs); err != nil {
+	// Recover a seed set of function and basic block addresses by
+	// recursive-descent disassembly, so the JSON oracle files and external
+	// oracles below are only needed to augment or override the
+	// automatically discovered analysis. This is the one control-flow-driven
+	// discovery pass in the tool; cmd/x no longer carries its own copy of the
+	// algorithm, it calls into disasm/x86/discover so the logic is defined
+	// once and reused by whatever else wants it (tests, other front-ends).
+	disc, err := discover.Discover(file)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	sort.Sort(l.funcAddrs)
-	// Parse basic block addresses.
-	if err := parseJSON("blocks.json", &l.blockAddrs); err != nil {
+	l.chunks = disc.Chunks
+
+	jsonOracle, err := newJSONOracle("funcs.json", "blocks.json")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	oracles = append(oracles, jsonOracle, newSymOracle(file))
+
+	var funcAddrs, blockAddrs bin.Addrs
+	funcAddrs = append(funcAddrs, disc.FuncAddrs...)
+	blockAddrs = append(blockAddrs, disc.BlockAddrs...)
+	for _, o := range oracles {
+		funcAddrs = append(funcAddrs, o.FuncAddrs()...)
+		blockAddrs = append(blockAddrs, o.BlockAddrs()...)
+	}
+
+	// When lifting a Go binary, recover precise function names and entry
+	// addresses from the pclntab, which take precedence over the generic
+	// discovery pass above. This goes through the same goOracle consulted
+	// for externally-supplied oracles, rather than calling bin/gobin
+	// directly, so there is a single place that turns a parsed pclntab into
+	// function addresses and names.
+	if goOracle, isGo, err := newGoOracle(file); err != nil {
 		return nil, errors.WithStack(err)
+	} else if isGo {
+		funcAddrs = append(funcAddrs, goOracle.FuncAddrs()...)
+		l.x86.SetGoNames(goOracle.names)
+		l.buildInfo = goOracle.buildInfo
 	}
-	sort.Sort(l.blockAddrs)
-	// Parse non-continuous basic block addresses.
-	if err := parseJSON("chunks.json", &l.chunks); err != nil {
+	l.x86.SetFuncAddrs(funcAddrs)
+	l.x86.SetBlockAddrs(blockAddrs)
+	// Parse non-continuous basic block addresses; an optional
+	// override/augmentation of the chunks discovered above.
+	jsonChunks := make(map[bin.Addr]map[bin.Addr]bool)
+	if err := parseJSON("chunks.json", &jsonChunks); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	for blockAddr, chunk := range jsonChunks {
+		if l.chunks[blockAddr] == nil {
+			l.chunks[blockAddr] = make(map[bin.Addr]bool)
+		}
+		for funcAddr, ok := range chunk {
+			l.chunks[blockAddr][funcAddr] = ok
+		}
+	}
+	l.x86.SetChunks(l.chunks)
 	return l, nil
 }
 
-// lift lifts the given binary executable to LLVM IR assembly.
-func (l *lifter) lift() (*ir.Module, error) {
+// lift lifts the binary executable to LLVM IR assembly.
+func (l *lifter) lift() error {
 	dbg.Printf("lift(binPath = %q)", l.binPath)
-	// Parse PE file.
-	file, err := pe.Open(l.binPath)
+	defer l.file.Close()
+	// Decode x86 instructions of binary executable.
+	sects, err := l.file.Sections()
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return errors.WithStack(err)
 	}
-	defer file.Close()
-	optHdr, ok := file.OptionalHeader.(*pe.OptionalHeader32)
-	if !ok {
-		return nil, errors.New("support for 64-bit executables not yet implemented")
-	}
-	base := Addr(optHdr.ImageBase)
-	// Decode x86 instructions of binary executable.
-	for _, sect := range file.Sections {
-		data, err := sect.Data()
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
+	for _, sect := range sects {
 		dbg.Printf("=== [ section %q ] ===", sect.Name)
-		switch {
-		case isExec(sect):
-			rel := Addr(sect.VirtualAddress)
-			addr := base + rel
-			if err := l.decodeCodeSection(addr, data); err != nil {
-				return nil, errors.WithStack(err)
-			}
+		if sect.Perm&bin.PermX == 0 {
+			continue
+		}
+		if err := l.x86.DecodeCodeSection(sect.Addr, sect.Data); err != nil {
+			return errors.WithStack(err)
 		}
 	}
+	if err := l.writeFuncs(l.x86.Funcs()); err != nil {
+		return errors.WithStack(err)
+	}
 	// Translate x86 binary executable to LLVM IR module.
 	m, err := l.translate()
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return errors.WithStack(err)
+	}
+	return l.writeModule(m)
+}
+
+// writeModule writes the LLVM IR assembly of m to a ".ll" file derived from
+// the binary executable's path.
+func (l *lifter) writeModule(m *ir.Module) error {
+	llPath := l.binPath + ".ll"
+	dbg.Printf("writing LLVM IR assembly to %q", llPath)
+	if err := ioutil.WriteFile(llPath, []byte(m.String()), 0644); err != nil {
+		return errors.WithStack(err)
 	}
-	return m, nil
+	return nil
 }
@@ -4,13 +4,11 @@ import (
 	"fmt"
 	"sort"
 
-	"github.com/kr/pretty"
 	"github.com/llir/llvm/ir"
-	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/metadata"
 	"github.com/llir/llvm/ir/types"
-	"github.com/llir/llvm/ir/value"
+	"github.com/mewmew/x/bin"
 	"github.com/pkg/errors"
-	"golang.org/x/arch/x86/x86asm"
 )
 
 // translate translates the given x86 binary executable into an equivalent LLVM
@@ -19,28 +17,76 @@ func (l *lifter) translate() (*ir.Module, error) {
 	// Index functions.
 	l.indexFuncs()
 
-	// Create LLVM IR module.
+	// Lift the body of each indexed function.
+	if err := l.liftFuncs(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Create LLVM IR module, attaching one *ir.Func per lifted function, in
+	// address order for deterministic output.
 	// TODO: move ir.NewModule to newLifter and move m to a lifter field?
 	m := ir.NewModule()
+	var addrs bin.Addrs
+	for addr := range l.funcs {
+		addrs = append(addrs, addr)
+	}
+	sort.Sort(addrs)
+	for _, addr := range addrs {
+		m.Funcs = append(m.Funcs, l.funcs[addr])
+	}
+	l.attachBuildInfo(m)
 	return m, nil
 }
 
+// attachBuildInfo records the Go module path, version and toolchain
+// recovered from the binary's build info blob (if any) as named metadata on
+// m, under "mewmew.gobin.buildinfo", so the information survives into the
+// emitted LLVM IR assembly instead of being discarded after lifting.
+func (l *lifter) attachBuildInfo(m *ir.Module) {
+	if l.buildInfo == nil {
+		return
+	}
+	fields := []metadata.Field{
+		&metadata.String{Value: l.buildInfo.GoVersion},
+		&metadata.String{Value: l.buildInfo.Path},
+		&metadata.String{Value: l.buildInfo.Version},
+	}
+	tuple := &metadata.Tuple{MetadataID: -1, Fields: fields}
+	m.MetadataDefs = append(m.MetadataDefs, tuple)
+	const mdName = "mewmew.gobin.buildinfo"
+	m.NamedMetadataDefs[mdName] = &metadata.NamedDef{
+		Name:  mdName,
+		Nodes: []metadata.Node{tuple},
+	}
+}
+
 // indexFunc indexes the LLVM IR function definitions based on function address.
 func (l *lifter) indexFuncs() {
-	// TODO: handle function signatures.
-	for _, asmFunc := range l.asmFuncs {
-		funcName := fmt.Sprintf("func_%08X", uint32(asmFunc.entry))
-		f := ir.NewFunction(funcName, types.Void)
-		l.funcs[asmFunc.entry] = f
+	for _, asmFunc := range l.x86.Funcs() {
+		funcName := asmFunc.GoName
+		if funcName == "" {
+			funcName = fmt.Sprintf("func_%08X", uint32(asmFunc.Entry))
+		}
+		var retType types.Type = types.Void
+		if asmFunc.Signature != nil {
+			retType = asmFunc.Signature.Ret
+		}
+		f := ir.NewFunc(funcName, retType)
+		if asmFunc.Signature != nil {
+			for i, paramType := range asmFunc.Signature.Params {
+				f.Params = append(f.Params, ir.NewParam(fmt.Sprintf("arg%d", i), paramType))
+			}
+		}
+		l.funcs[asmFunc.Entry] = f
 	}
 }
 
 // liftFuncs lifts the given x86 functions to equivalent LLVM IR functions.
 func (l *lifter) liftFuncs() error {
-	for _, asmFunc := range l.asmFuncs {
-		f, ok := l.funcs[asmFunc.entry]
+	for _, asmFunc := range l.x86.Funcs() {
+		f, ok := l.funcs[asmFunc.Entry]
 		if !ok {
-			return errors.Errorf("unable to locate function at %v", asmFunc.entry)
+			return errors.Errorf("unable to locate function at %v", asmFunc.Entry)
 		}
 		fl := newFuncLifter(l, f)
 		if err := fl.liftFunc(asmFunc); err != nil {
@@ -49,70 +95,3 @@ func (l *lifter) liftFuncs() error {
 	}
 	return nil
 }
-
-// liftFunc lifts the given x86 function to an equivalent LLVM IR function.
-func (fl *funcLifter) liftFunc(asmFunc *Function) error {
-	var keys Addrs
-	for key := range asmFunc.blocks {
-		keys = append(keys, key)
-	}
-	sort.Sort(keys)
-	for _, key := range keys {
-		asmBlock := asmFunc.blocks[key]
-		if err := fl.liftBlock(asmBlock); err != nil {
-			return errors.WithStack(err)
-		}
-	}
-	return nil
-}
-
-// liftBlock lifts the given x86 basic block to an equivalent LLVM IR basic
-// block.
-func (l *lifter) liftBlock(f *ir.Function, asmBlock *BasicBlock) error {
-	blockName := fmt.Sprintf("block_%08X", uint32(asmBlock.Entry()))
-	llBlock := ir.NewBlock(blockName)
-	for _, asmInst := range asmBlock.insts {
-		llInst, err := l.translateInst(asmInst)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		// TODO: handle terminators.
-		llBlock.Insts = append(llBlock.Insts, llInst)
-	}
-	return llBlock, nil
-}
-
-// translateInst translates the given x86 instruction to an equivalent LLVM IR
-// instruction.
-func (l *lifter) translateInst(inst *Instruction) (ir.Instruction, error) {
-	pretty.Println("inst:", inst)
-	switch inst.Op {
-	case x86asm.JMP:
-		return l.translateInstJMP(inst)
-	default:
-		panic(fmt.Errorf("support for instruction %v not yet implemented; unable to translate instruction at %v", inst.Op, inst.addr))
-	}
-}
-
-// translateInstJMP translates the given x86 JMP instruction to an equivalent
-// LLVM IR instruction.
-func (l *lifter) translateInstJMP(inst *Instruction) (ir.Instruction, error) {
-	arg, err := l.translateArg(inst, inst.Args[0])
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
-	pretty.Println("arg:", arg)
-	return nil, nil
-}
-
-// translateArg translates the given x86 instruction argument to an equivalent
-// LLVM IR value.
-func (l *lifter) translateArg(inst *Instruction, arg x86asm.Arg) (value.Value, error) {
-	switch arg := arg.(type) {
-	case x86asm.Rel:
-		relAddr := int64(inst.addr) + int64(inst.Len) + int64(arg)
-		return constant.NewInt(types.I32, relAddr), nil
-	default:
-		panic(fmt.Errorf("support for instruction argument %T not yet implemented; unable to translate argument used in instruction at %v", arg, inst.addr))
-	}
-}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+)
+
+// Oracle supplies addresses and type information for a binary executable,
+// sourced either from an external tool (a hand-authored JSON file, IDA Pro,
+// Ghidra, radare2) or computed directly from the executable itself (the
+// symbol table). Oracles are best-effort; an Oracle that cannot answer a
+// given query returns the zero value rather than an error.
+type Oracle interface {
+	// FuncAddrs returns the addresses of known function entry points.
+	FuncAddrs() bin.Addrs
+	// BlockAddrs returns the addresses of known basic block entry points.
+	BlockAddrs() bin.Addrs
+	// CallingConv returns the calling convention of the function at addr,
+	// or "" if unknown.
+	CallingConv(addr bin.Addr) string
+	// FuncSig returns the C-like function signature of the function at
+	// addr, or "" if unknown.
+	FuncSig(addr bin.Addr) string
+	// TypeOf returns the type of the value at addr, or "" if unknown.
+	TypeOf(addr bin.Addr) string
+}
+
+// callingConv returns the calling convention of the function at addr,
+// consulting the given oracles in order and returning the first non-empty
+// answer.
+func callingConv(oracles []Oracle, addr bin.Addr) string {
+	for _, o := range oracles {
+		if cc := o.CallingConv(addr); cc != "" {
+			return cc
+		}
+	}
+	return ""
+}
+
+// funcSig returns the C-like function signature of the function at addr,
+// consulting the given oracles in order and returning the first non-empty
+// answer.
+func funcSig(oracles []Oracle, addr bin.Addr) string {
+	for _, o := range oracles {
+		if sig := o.FuncSig(addr); sig != "" {
+			return sig
+		}
+	}
+	return ""
+}
+
+// typeOf returns the type of the value at addr, consulting the given
+// oracles in order and returning the first non-empty answer.
+func typeOf(oracles []Oracle, addr bin.Addr) string {
+	for _, o := range oracles {
+		if t := o.TypeOf(addr); t != "" {
+			return t
+		}
+	}
+	return ""
+}
+
+// ### [ JSON oracle ] #########################################################
+
+// jsonOracle is an Oracle backed by hand-authored funcs.json and
+// blocks.json files, each containing a JSON array of addresses.
+type jsonOracle struct {
+	funcAddrs  bin.Addrs
+	blockAddrs bin.Addrs
+}
+
+// newJSONOracle returns a new jsonOracle based on the named funcs.json and
+// blocks.json files; either file may be absent, in which case the
+// corresponding addresses are left empty.
+func newJSONOracle(funcsPath, blocksPath string) (*jsonOracle, error) {
+	o := &jsonOracle{}
+	if err := decodeOptionalJSON(funcsPath, &o.funcAddrs); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := decodeOptionalJSON(blocksPath, &o.blockAddrs); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return o, nil
+}
+
+func (o *jsonOracle) FuncAddrs() bin.Addrs  { return o.funcAddrs }
+func (o *jsonOracle) BlockAddrs() bin.Addrs { return o.blockAddrs }
+
+// CallingConv is not present in the funcs.json/blocks.json schema.
+//
+// TODO: extend funcs.json with a calling convention field once the lift
+// pipeline needs it.
+func (o *jsonOracle) CallingConv(addr bin.Addr) string { return "" }
+
+// FuncSig is not present in the funcs.json/blocks.json schema.
+func (o *jsonOracle) FuncSig(addr bin.Addr) string { return "" }
+
+// TypeOf is not present in the funcs.json/blocks.json schema.
+func (o *jsonOracle) TypeOf(addr bin.Addr) string { return "" }
+
+var _ Oracle = (*jsonOracle)(nil)
+
+// ### [ Symbol table oracle ] #################################################
+
+// symOracle is an Oracle backed by the symbol table read directly from the
+// binary executable (the ELF symbol table, the Mach-O LC_SYMTAB symbols, the
+// COFF symbol table for PE), plus its exported symbols.
+type symOracle struct {
+	funcAddrs bin.Addrs
+}
+
+// newSymOracle returns a new symOracle backed by the symbol table of file.
+func newSymOracle(file bin.File) *symOracle {
+	o := &symOracle{}
+	for _, sym := range file.Symbols() {
+		o.funcAddrs = append(o.funcAddrs, sym.Addr)
+	}
+	for _, sym := range file.Exports() {
+		o.funcAddrs = append(o.funcAddrs, sym.Addr)
+	}
+	return o
+}
+
+func (o *symOracle) FuncAddrs() bin.Addrs { return o.funcAddrs }
+
+// BlockAddrs is always empty, as symbol tables do not carry basic block
+// granularity.
+func (o *symOracle) BlockAddrs() bin.Addrs { return nil }
+
+// CallingConv is not yet implemented.
+//
+// TODO: derive the calling convention from DWARF's DW_AT_calling_convention
+// attribute, or from PE import library metadata.
+func (o *symOracle) CallingConv(addr bin.Addr) string { return "" }
+
+// FuncSig is not yet implemented.
+//
+// TODO: derive the function signature from DWARF DW_TAG_subprogram debug
+// information, once exposed through bin.File.
+func (o *symOracle) FuncSig(addr bin.Addr) string { return "" }
+
+// TypeOf is not yet implemented.
+func (o *symOracle) TypeOf(addr bin.Addr) string { return "" }
+
+var _ Oracle = (*symOracle)(nil)
+
+// ### [ Ghidra oracle ] #######################################################
+
+// ghidraProgram is the subset of a Ghidra XML export (File ‣ Export Program
+// ‣ XML) that ghidraOracle parses.
+type ghidraProgram struct {
+	XMLName   xml.Name `xml:"PROGRAM"`
+	Functions []struct {
+		EntryPoint string `xml:"ENTRY_POINT,attr"`
+	} `xml:"FUNCTIONS>FUNCTION"`
+}
+
+// ghidraOracle is an Oracle backed by a Ghidra XML program export.
+type ghidraOracle struct {
+	funcAddrs bin.Addrs
+}
+
+// newGhidraOracle parses the named Ghidra XML program export.
+func newGhidraOracle(xmlPath string) (*ghidraOracle, error) {
+	f, err := os.Open(xmlPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+	var prog ghidraProgram
+	if err := xml.NewDecoder(f).Decode(&prog); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	o := &ghidraOracle{}
+	for _, fn := range prog.Functions {
+		var addr bin.Addr
+		if err := addr.UnmarshalText([]byte(fn.EntryPoint)); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse entry point of function %q", fn.EntryPoint)
+		}
+		o.funcAddrs = append(o.funcAddrs, addr)
+	}
+	return o, nil
+}
+
+func (o *ghidraOracle) FuncAddrs() bin.Addrs { return o.funcAddrs }
+
+// BlockAddrs is always empty; the default Ghidra XML export does not
+// include basic block granularity.
+//
+// TODO: recover basic block addresses from a Ghidra XML export once a
+// block-level export script is in place.
+func (o *ghidraOracle) BlockAddrs() bin.Addrs { return nil }
+
+// CallingConv is not yet implemented.
+func (o *ghidraOracle) CallingConv(addr bin.Addr) string { return "" }
+
+// FuncSig is not yet implemented.
+//
+// TODO: parse the FUNCTION element's SIGNATURE child once present in the
+// export.
+func (o *ghidraOracle) FuncSig(addr bin.Addr) string { return "" }
+
+// TypeOf is not yet implemented.
+func (o *ghidraOracle) TypeOf(addr bin.Addr) string { return "" }
+
+var _ Oracle = (*ghidraOracle)(nil)
+
+// ### [ radare2 oracle ] ######################################################
+
+// r2Func is a single entry of an `aflj` (analyze functions list json) dump.
+type r2Func struct {
+	Offset uint64 `json:"offset"`
+	Name   string `json:"name"`
+}
+
+// r2Block is a single entry of an `afbj` (analyze basic blocks list json)
+// dump.
+type r2Block struct {
+	Addr uint64 `json:"addr"`
+}
+
+// radare2Oracle is an Oracle backed by `aflj`/`afbj` JSON dumps produced by
+// radare2 (`r2 -c 'aaa; aflj' -q0 binary > funcs.json`).
+type radare2Oracle struct {
+	funcAddrs  bin.Addrs
+	blockAddrs bin.Addrs
+}
+
+// newRadare2Oracle parses the named aflj and afbj JSON dumps; either path
+// may be empty, in which case the corresponding addresses are left empty.
+func newRadare2Oracle(affljPath, afbjPath string) (*radare2Oracle, error) {
+	o := &radare2Oracle{}
+	if affljPath != "" {
+		var funcs []r2Func
+		if err := decodeJSON(affljPath, &funcs); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, fn := range funcs {
+			o.funcAddrs = append(o.funcAddrs, bin.Addr(fn.Offset))
+		}
+	}
+	if afbjPath != "" {
+		var blocks []r2Block
+		if err := decodeJSON(afbjPath, &blocks); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, block := range blocks {
+			o.blockAddrs = append(o.blockAddrs, bin.Addr(block.Addr))
+		}
+	}
+	return o, nil
+}
+
+func (o *radare2Oracle) FuncAddrs() bin.Addrs  { return o.funcAddrs }
+func (o *radare2Oracle) BlockAddrs() bin.Addrs { return o.blockAddrs }
+
+// CallingConv is not yet implemented.
+//
+// TODO: read the "calltype" field of the aflj entry once calling
+// convention inference is needed.
+func (o *radare2Oracle) CallingConv(addr bin.Addr) string { return "" }
+
+// FuncSig is not yet implemented.
+//
+// TODO: read the "signature" field of the aflj entry.
+func (o *radare2Oracle) FuncSig(addr bin.Addr) string { return "" }
+
+// TypeOf is not yet implemented.
+func (o *radare2Oracle) TypeOf(addr bin.Addr) string { return "" }
+
+var _ Oracle = (*radare2Oracle)(nil)
+
+// ### [ IDA Pro oracle ] ######################################################
+
+// idaFunc is a single entry of an IDA Pro function list, exported to JSON
+// by a companion .idc/IDAPython script (e.g. idautils.Functions()).
+type idaFunc struct {
+	Start bin.Addr `json:"start"`
+	Name  string   `json:"name"`
+}
+
+// idaOracle is an Oracle backed by a JSON export of an IDA Pro function
+// list.
+type idaOracle struct {
+	funcAddrs bin.Addrs
+}
+
+// newIDAOracle parses the named IDA Pro function list JSON export.
+func newIDAOracle(jsonPath string) (*idaOracle, error) {
+	var funcs []idaFunc
+	if err := decodeJSON(jsonPath, &funcs); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	o := &idaOracle{}
+	for _, fn := range funcs {
+		o.funcAddrs = append(o.funcAddrs, fn.Start)
+	}
+	return o, nil
+}
+
+func (o *idaOracle) FuncAddrs() bin.Addrs { return o.funcAddrs }
+
+// BlockAddrs is always empty.
+//
+// TODO: recover basic block addresses once a block-granularity .idc export
+// script is written; idautils.Functions() only enumerates function entry
+// points natively.
+func (o *idaOracle) BlockAddrs() bin.Addrs { return nil }
+
+// CallingConv is not yet implemented.
+func (o *idaOracle) CallingConv(addr bin.Addr) string { return "" }
+
+// FuncSig is not yet implemented.
+//
+// TODO: export idc.get_type(ea) alongside the function list.
+func (o *idaOracle) FuncSig(addr bin.Addr) string { return "" }
+
+// TypeOf is not yet implemented.
+func (o *idaOracle) TypeOf(addr bin.Addr) string { return "" }
+
+var _ Oracle = (*idaOracle)(nil)
+
+// ### [ helper functions ] ####################################################
+
+// decodeJSON decodes the named JSON file into v.
+func decodeJSON(jsonPath string, v interface{}) error {
+	f, err := os.Open(jsonPath)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	if err := dec.Decode(v); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
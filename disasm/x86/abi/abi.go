@@ -0,0 +1,110 @@
+// Package abi models x86 calling conventions and function signatures, so
+// that CALL/RET lowering and argument marshalling can be done per ABI rather
+// than assuming a single fixed convention.
+package abi
+
+import (
+	"github.com/llir/llvm/ir/types"
+)
+
+// Conv specifies a calling convention.
+type Conv uint8
+
+// Calling conventions.
+const (
+	// ConvNone is the zero value, used when the calling convention of a
+	// function has not yet been determined.
+	ConvNone Conv = iota
+	// Cdecl is the C declaration calling convention; the caller cleans up the
+	// stack, arguments are passed right-to-left.
+	Cdecl
+	// Stdcall is the standard calling convention used throughout the Win32
+	// API; the callee cleans up the stack using `ret N`.
+	Stdcall
+	// Fastcall passes the first two integer/pointer arguments in ECX and
+	// EDX, remaining arguments on the stack; the callee cleans up the stack.
+	Fastcall
+	// Thiscall passes the `this` pointer in ECX, remaining arguments on the
+	// stack; used by non-variadic C++ instance methods on x86-32.
+	Thiscall
+	// SysVAMD64 is the System V AMD64 ABI used on Linux, *BSD and macOS;
+	// integer/pointer arguments are passed in RDI, RSI, RDX, RCX, R8, R9.
+	SysVAMD64
+	// Win64 is the Microsoft x64 calling convention; integer/pointer
+	// arguments are passed in RCX, RDX, R8, R9.
+	Win64
+)
+
+// String returns the string representation of the calling convention.
+func (conv Conv) String() string {
+	switch conv {
+	case ConvNone:
+		return "none"
+	case Cdecl:
+		return "cdecl"
+	case Stdcall:
+		return "stdcall"
+	case Fastcall:
+		return "fastcall"
+	case Thiscall:
+		return "thiscall"
+	case SysVAMD64:
+		return "sysv_amd64"
+	case Win64:
+		return "win64"
+	default:
+		return "unknown"
+	}
+}
+
+// IntArgRegs returns the (ordered) general-purpose registers used to pass
+// the leading integer/pointer arguments of conv, or nil if conv passes all
+// arguments on the stack.
+func (conv Conv) IntArgRegs() []string {
+	switch conv {
+	case Fastcall:
+		return []string{"ECX", "EDX"}
+	case Thiscall:
+		return []string{"ECX"}
+	case SysVAMD64:
+		return []string{"RDI", "RSI", "RDX", "RCX", "R8", "R9"}
+	case Win64:
+		return []string{"RCX", "RDX", "R8", "R9"}
+	default:
+		return nil
+	}
+}
+
+// CalleeCleanup reports whether the callee is responsible for popping
+// arguments off the stack before returning (as opposed to the caller).
+func (conv Conv) CalleeCleanup() bool {
+	switch conv {
+	case Stdcall, Fastcall, Thiscall:
+		return true
+	default:
+		return false
+	}
+}
+
+// Signature describes the parameters, return type and calling convention of
+// a function.
+type Signature struct {
+	// Parameter types, in source (left-to-right) order.
+	Params []types.Type
+	// Return type.
+	Ret types.Type
+	// Calling convention.
+	Conv Conv
+	// Variadic specifies whether the function takes a variable number of
+	// arguments (e.g. cdecl functions such as printf).
+	Variadic bool
+}
+
+// NewSignature returns a new signature with a void return type, an unknown
+// calling convention (ConvNone), and no recorded parameters.
+func NewSignature() *Signature {
+	return &Signature{
+		Ret:  types.Void,
+		Conv: ConvNone,
+	}
+}
@@ -0,0 +1,130 @@
+package abi
+
+import (
+	"github.com/llir/llvm/ir/types"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// Infer infers the signature of a function from the prologue and epilogues
+// of its entry and return basic blocks, by pattern-matching on the common
+// `push ebp; mov ebp, esp` frame setup, `ret N` stack cleanup used by
+// stdcall/fastcall/thiscall, and argument reads off `[ebp+8]`, `[ebp+12]`,
+// etc.
+//
+// Infer is a heuristic of last resort; explicit JSON overrides and
+// PDB/DWARF-sourced signatures should always take precedence over it.
+func Infer(bitness int, entry, ret []x86asm.Inst) *Signature {
+	sig := NewSignature()
+	framePtr := hasFramePointerProlog(entry)
+	cleanup := stackCleanup(ret)
+	numArgs := 0
+	switch {
+	case bitness == 64:
+		// The SysV/Win64 ABIs are selected by the target platform, not the
+		// function itself; default to SysV here and let the caller override
+		// based on the binary's OS/ABI. Both ABIs pass the first several
+		// arguments in registers (rdi/rsi/rdx/rcx/r8/r9 for SysV,
+		// rcx/rdx/r8/r9 for Win64), which this prologue/epilogue heuristic
+		// does not yet recover (TODO); leave numArgs at 0 rather than
+		// running countEbpArgs's x86-32 4-byte-stack-slot-stride heuristic
+		// against a 64-bit frame, which would fabricate bogus i32 params.
+		sig.Conv = SysVAMD64
+	case cleanup > 0:
+		sig.Conv = Stdcall
+		numArgs = cleanup / 4
+	case framePtr:
+		sig.Conv = Cdecl
+		numArgs = countEbpArgs(entry)
+	default:
+		sig.Conv = ConvNone
+	}
+	for i := 0; i < numArgs; i++ {
+		sig.Params = append(sig.Params, types.I32)
+	}
+	if retsEax(ret) {
+		sig.Ret = types.I32
+	}
+	return sig
+}
+
+// hasFramePointerProlog reports whether insts opens with the canonical
+// `push ebp; mov ebp, esp` (or `push rbp; mov rbp, rsp`) frame setup.
+func hasFramePointerProlog(insts []x86asm.Inst) bool {
+	if len(insts) < 2 {
+		return false
+	}
+	push, ok := insts[0].Args[0].(x86asm.Reg)
+	if insts[0].Op != x86asm.PUSH || !ok || (push != x86asm.EBP && push != x86asm.RBP) {
+		return false
+	}
+	if insts[1].Op != x86asm.MOV {
+		return false
+	}
+	dst, ok1 := insts[1].Args[0].(x86asm.Reg)
+	src, ok2 := insts[1].Args[1].(x86asm.Reg)
+	if !ok1 || !ok2 {
+		return false
+	}
+	return (dst == x86asm.EBP && src == x86asm.ESP) || (dst == x86asm.RBP && src == x86asm.RSP)
+}
+
+// countEbpArgs returns the number of 4-byte stack argument slots from
+// `[ebp+8]` up to and including the highest `[ebp+N]` (N >= 8) memory operand
+// referenced by insts, a rough proxy for the number of stack arguments of
+// the function.
+func countEbpArgs(insts []x86asm.Inst) int {
+	seen := make(map[int64]bool)
+	for _, inst := range insts {
+		for _, arg := range inst.Args {
+			mem, ok := arg.(x86asm.Mem)
+			if !ok {
+				continue
+			}
+			if mem.Base != x86asm.EBP && mem.Base != x86asm.RBP {
+				continue
+			}
+			if mem.Disp >= 8 {
+				seen[mem.Disp] = true
+			}
+		}
+	}
+	// Each argument slot is 4 (x86-32) bytes wide, starting at ebp+8.
+	max := int64(4)
+	for disp := range seen {
+		if disp > max {
+			max = disp
+		}
+	}
+	return int((max-8)/4 + 1)
+}
+
+// stackCleanup returns the immediate operand N of a `ret N` instruction among
+// insts, used by stdcall/fastcall/thiscall functions to pop their own
+// arguments, or 0 if no such instruction is found.
+func stackCleanup(insts []x86asm.Inst) int {
+	for _, inst := range insts {
+		if inst.Op != x86asm.RET || len(inst.Args) == 0 {
+			continue
+		}
+		if imm, ok := inst.Args[0].(x86asm.Imm); ok {
+			return int(imm)
+		}
+	}
+	return 0
+}
+
+// retsEax reports whether insts writes to EAX/RAX before returning, used as
+// a heuristic for a non-void return type.
+func retsEax(insts []x86asm.Inst) bool {
+	for _, inst := range insts {
+		if len(inst.Args) == 0 {
+			continue
+		}
+		if reg, ok := inst.Args[0].(x86asm.Reg); ok {
+			if reg == x86asm.EAX || reg == x86asm.RAX {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package abi
+
+import (
+	"testing"
+
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// framePointerProlog returns the canonical `push ebp/rbp; mov ebp/rbp,
+// esp/rsp` prologue for the given bitness.
+func framePointerProlog(bitness int) []x86asm.Inst {
+	bp, sp := x86asm.EBP, x86asm.ESP
+	if bitness == 64 {
+		bp, sp = x86asm.RBP, x86asm.RSP
+	}
+	return []x86asm.Inst{
+		{Op: x86asm.PUSH, Args: x86asm.Args{bp}},
+		{Op: x86asm.MOV, Args: x86asm.Args{bp, sp}},
+	}
+}
+
+// ebpArg returns a `mov eax, [ebp+disp]`-shaped instruction referencing a
+// stack argument at the given displacement off EBP.
+func ebpArg(disp int64) x86asm.Inst {
+	return x86asm.Inst{
+		Op:   x86asm.MOV,
+		Args: x86asm.Args{x86asm.EAX, x86asm.Mem{Base: x86asm.EBP, Disp: disp}},
+	}
+}
+
+func retN(n int64) x86asm.Inst {
+	return x86asm.Inst{Op: x86asm.RET, Args: x86asm.Args{x86asm.Imm(n)}}
+}
+
+func movEax() x86asm.Inst {
+	return x86asm.Inst{Op: x86asm.MOV, Args: x86asm.Args{x86asm.EAX, x86asm.Imm(0)}}
+}
+
+func TestInferCdecl(t *testing.T) {
+	entry := append(framePointerProlog(32), ebpArg(8), ebpArg(12))
+	ret := []x86asm.Inst{movEax(), {Op: x86asm.RET}}
+	sig := Infer(32, entry, ret)
+	if sig.Conv != Cdecl {
+		t.Fatalf("Conv: got %v, want %v", sig.Conv, Cdecl)
+	}
+	if len(sig.Params) != 2 {
+		t.Fatalf("Params: got %d, want 2", len(sig.Params))
+	}
+	if sig.Ret.String() != "i32" {
+		t.Fatalf("Ret: got %v, want i32", sig.Ret)
+	}
+}
+
+func TestInferStdcall(t *testing.T) {
+	entry := append(framePointerProlog(32), ebpArg(8), ebpArg(12))
+	ret := []x86asm.Inst{retN(8)}
+	sig := Infer(32, entry, ret)
+	if sig.Conv != Stdcall {
+		t.Fatalf("Conv: got %v, want %v", sig.Conv, Stdcall)
+	}
+	if len(sig.Params) != 2 {
+		t.Fatalf("Params: got %d, want 2 (cleanup 8 / 4)", len(sig.Params))
+	}
+}
+
+func TestInferConvNone(t *testing.T) {
+	entry := []x86asm.Inst{{Op: x86asm.NOP}}
+	ret := []x86asm.Inst{{Op: x86asm.RET}}
+	sig := Infer(32, entry, ret)
+	if sig.Conv != ConvNone {
+		t.Fatalf("Conv: got %v, want %v", sig.Conv, ConvNone)
+	}
+	if len(sig.Params) != 0 {
+		t.Fatalf("Params: got %d, want 0", len(sig.Params))
+	}
+}
+
+// TestInfer64NoStackParams verifies that a 64-bit function with a frame
+// pointer prologue does not have stack-argument params fabricated from the
+// x86-32 4-byte-stride heuristic, which does not apply to a 64-bit frame.
+func TestInfer64NoStackParams(t *testing.T) {
+	entry := append(framePointerProlog(64), x86asm.Inst{
+		Op:   x86asm.MOV,
+		Args: x86asm.Args{x86asm.EAX, x86asm.Mem{Base: x86asm.RBP, Disp: 16}},
+	})
+	ret := []x86asm.Inst{{Op: x86asm.RET}}
+	sig := Infer(64, entry, ret)
+	if sig.Conv != SysVAMD64 {
+		t.Fatalf("Conv: got %v, want %v", sig.Conv, SysVAMD64)
+	}
+	if len(sig.Params) != 0 {
+		t.Fatalf("Params: got %d, want 0 (register-passed args not yet recovered)", len(sig.Params))
+	}
+}
+
+func TestInferVoidReturn(t *testing.T) {
+	entry := framePointerProlog(32)
+	ret := []x86asm.Inst{{Op: x86asm.RET}}
+	sig := Infer(32, entry, ret)
+	if sig.Ret.String() != "void" {
+		t.Fatalf("Ret: got %v, want void", sig.Ret)
+	}
+}
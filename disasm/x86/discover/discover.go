@@ -0,0 +1,278 @@
+// Package discover recovers function and basic block boundaries from a
+// binary executable by recursive-descent disassembly, so that lifting no
+// longer depends on hand-authored funcs.json/blocks.json/chunks.json oracle
+// files.
+package discover
+
+import (
+	"log"
+	"os"
+	"sort"
+
+	"github.com/mewkiz/pkg/term"
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// dbg is a logger which logs debug messages with "discover:" prefix to
+// standard error.
+var dbg = log.New(os.Stderr, term.MagentaBold("discover:")+" ", 0)
+
+// Result holds the function and basic block addresses recovered from a
+// binary executable, in the same shape consumed by x86.Lifter.
+type Result struct {
+	// FuncAddrs are the addresses of discovered function entry points, sorted
+	// in ascending order.
+	FuncAddrs bin.Addrs
+	// BlockAddrs are the addresses of discovered basic block entry points,
+	// sorted in ascending order.
+	BlockAddrs bin.Addrs
+	// Chunks maps from basic block address to the set of non-continuous
+	// functions that basic block belongs to; populated when an unconditional
+	// jump is observed to land inside another function's address range.
+	Chunks map[bin.Addr]map[bin.Addr]bool
+}
+
+// section describes an executable section of the binary, used to resolve
+// addresses to raw bytes during decoding.
+type section struct {
+	addr bin.Addr
+	data []byte
+}
+
+// discoverer performs recursive-descent disassembly of a binary executable to
+// recover function and basic block boundaries.
+type discoverer struct {
+	// Executable sections of the binary.
+	sects []section
+	// x86asm decode mode (16, 32 or 64), derived from the bitness of the
+	// binary executable.
+	cpuMode int
+
+	// funcAddrs is the set of discovered function entry points.
+	funcAddrs map[bin.Addr]bool
+	// blockAddrs is the set of discovered basic block entry points.
+	blockAddrs map[bin.Addr]bool
+	// chunks maps from basic block address to the set of non-continuous
+	// functions that basic block belongs to.
+	chunks map[bin.Addr]map[bin.Addr]bool
+	// funcRanges records the [start, end) address range seen so far for each
+	// discovered function, used to detect non-continuous chunks.
+	funcRanges map[bin.Addr]struct{ start, end bin.Addr }
+}
+
+// workItem is a pending address to decode, tagged with the function it is
+// believed to belong to.
+type workItem struct {
+	addr     bin.Addr
+	funcAddr bin.Addr
+}
+
+// Discover recovers function and basic block boundaries for file by
+// recursive-descent disassembly, seeded from the entry point, exported
+// symbols and (when present) the symbol table of the binary executable.
+//
+// Discover is best-effort: indirect jumps/calls and jump tables are not
+// followed, so the result should be treated as a seed to layer hand-authored
+// JSON overrides on top of, not as a guarantee of complete coverage.
+func Discover(file bin.File) (*Result, error) {
+	d := &discoverer{
+		cpuMode:    file.Bitness(),
+		funcAddrs:  make(map[bin.Addr]bool),
+		blockAddrs: make(map[bin.Addr]bool),
+		chunks:     make(map[bin.Addr]map[bin.Addr]bool),
+		funcRanges: make(map[bin.Addr]struct{ start, end bin.Addr }),
+	}
+	sects, err := file.Sections()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, sect := range sects {
+		if sect.Perm&bin.PermX == 0 {
+			continue
+		}
+		d.sects = append(d.sects, section{addr: sect.Addr, data: sect.Data})
+	}
+	var seeds bin.Addrs
+	seeds = append(seeds, file.Entry())
+	for _, sym := range file.Exports() {
+		seeds = append(seeds, sym.Addr)
+	}
+	for _, sym := range file.Symbols() {
+		seeds = append(seeds, sym.Addr)
+	}
+	var work []workItem
+	for _, seed := range seeds {
+		if seed == 0 {
+			continue
+		}
+		work = append(work, workItem{addr: seed, funcAddr: seed})
+	}
+	for len(work) > 0 {
+		item := work[0]
+		work = work[1:]
+		more, err := d.decodeFunc(item.addr, item.funcAddr)
+		if err != nil {
+			dbg.Printf("unable to decode function at %v: %v", item.funcAddr, err)
+			continue
+		}
+		work = append(work, more...)
+	}
+	res := &Result{Chunks: d.chunks}
+	for addr := range d.funcAddrs {
+		res.FuncAddrs = append(res.FuncAddrs, addr)
+	}
+	for addr := range d.blockAddrs {
+		res.BlockAddrs = append(res.BlockAddrs, addr)
+	}
+	sort.Sort(res.FuncAddrs)
+	sort.Sort(res.BlockAddrs)
+	return res, nil
+}
+
+// decodeFunc recursively decodes the basic block starting at addr, believed
+// to belong to the function at funcAddr, and returns further work items
+// discovered from its terminator.
+func (d *discoverer) decodeFunc(addr, funcAddr bin.Addr) ([]workItem, error) {
+	d.funcAddrs[funcAddr] = true
+	if d.blockAddrs[addr] {
+		// Already discovered; nothing more to do.
+		return nil, nil
+	}
+	d.blockAddrs[addr] = true
+	sect := d.sectionContaining(addr)
+	if sect == nil {
+		return nil, errors.Errorf("unable to locate section containing address %v", addr)
+	}
+	var work []workItem
+	cur := addr
+	for {
+		offset := int(cur - sect.addr)
+		if offset < 0 || offset >= len(sect.data) {
+			return nil, errors.Errorf("address %v out of bounds of section", cur)
+		}
+		inst, err := x86asm.Decode(sect.data[offset:], d.cpuMode)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		next := cur + bin.Addr(inst.Len)
+		switch inst.Op {
+		case x86asm.CALL:
+			if target, ok := RelTarget(cur, inst); ok {
+				work = append(work, workItem{addr: target, funcAddr: target})
+			}
+		case x86asm.JMP:
+			if target, ok := RelTarget(cur, inst); ok {
+				owner := d.chunkOwner(funcAddr, target)
+				if owner != funcAddr {
+					d.recordEdge(funcAddr, target)
+				}
+				work = append(work, workItem{addr: target, funcAddr: owner})
+			}
+			d.updateRange(funcAddr, addr, next)
+			return work, nil
+		case x86asm.RET:
+			d.updateRange(funcAddr, addr, next)
+			return work, nil
+		default:
+			if IsCondJump(inst.Op) {
+				if target, ok := RelTarget(cur, inst); ok {
+					work = append(work, workItem{addr: target, funcAddr: funcAddr})
+				}
+				work = append(work, workItem{addr: next, funcAddr: funcAddr})
+				d.updateRange(funcAddr, addr, next)
+				return work, nil
+			}
+		}
+		cur = next
+	}
+}
+
+// chunkOwner returns the function address that the basic block at target
+// should be attributed to: funcAddr if target lies outside every function
+// range observed so far, or the owning function if target lands inside an
+// already-known function's range. chunkOwner records nothing itself; in the
+// latter case decodeFunc records the block as a non-continuous chunk of
+// funcAddr.
+func (d *discoverer) chunkOwner(funcAddr, target bin.Addr) bin.Addr {
+	for owner, rng := range d.funcRanges {
+		if owner == funcAddr {
+			continue
+		}
+		if rng.start <= target && target < rng.end {
+			return owner
+		}
+	}
+	return funcAddr
+}
+
+// recordEdge marks the basic block at target as belonging (at least) to
+// funcAddr, recording a non-continuous chunk when target is not the function
+// entry point.
+func (d *discoverer) recordEdge(funcAddr, target bin.Addr) {
+	if target == funcAddr {
+		return
+	}
+	if d.chunks[target] == nil {
+		d.chunks[target] = make(map[bin.Addr]bool)
+	}
+	d.chunks[target][funcAddr] = true
+}
+
+// updateRange grows the recorded [start, end) address range of funcAddr to
+// include [blockAddr, blockEnd).
+func (d *discoverer) updateRange(funcAddr, blockAddr, blockEnd bin.Addr) {
+	rng, ok := d.funcRanges[funcAddr]
+	if !ok {
+		d.funcRanges[funcAddr] = struct{ start, end bin.Addr }{start: blockAddr, end: blockEnd}
+		return
+	}
+	if blockAddr < rng.start {
+		rng.start = blockAddr
+	}
+	if blockEnd > rng.end {
+		rng.end = blockEnd
+	}
+	d.funcRanges[funcAddr] = rng
+}
+
+// sectionContaining returns the executable section containing addr, or nil
+// if no such section was found.
+func (d *discoverer) sectionContaining(addr bin.Addr) *section {
+	for i, sect := range d.sects {
+		if sect.addr <= addr && int(addr-sect.addr) < len(sect.data) {
+			return &d.sects[i]
+		}
+	}
+	return nil
+}
+
+// ### [ Helper functions ] ####################################################
+
+// RelTarget returns the absolute target address of a direct (PC-relative)
+// jump or call instruction, and a boolean indicating whether the instruction
+// had a PC-relative (rather than indirect) operand.
+//
+// Exported for reuse by cmd/x, which needs the same CFG-edge computation to
+// render and lower the functions this package discovers.
+func RelTarget(instAddr bin.Addr, inst x86asm.Inst) (bin.Addr, bool) {
+	if len(inst.Args) == 0 {
+		return 0, false
+	}
+	rel, ok := inst.Args[0].(x86asm.Rel)
+	if !ok {
+		return 0, false
+	}
+	return bin.Addr(int64(instAddr) + int64(inst.Len) + int64(rel)), true
+}
+
+// IsCondJump reports whether op is a conditional jump or loop opcode.
+func IsCondJump(op x86asm.Op) bool {
+	switch op {
+	case x86asm.JA, x86asm.JAE, x86asm.JB, x86asm.JBE, x86asm.JCXZ, x86asm.JE, x86asm.JECXZ, x86asm.JG, x86asm.JGE, x86asm.JL, x86asm.JLE, x86asm.JNE, x86asm.JNO, x86asm.JNP, x86asm.JNS, x86asm.JO, x86asm.JP, x86asm.JRCXZ, x86asm.JS,
+		x86asm.LOOP, x86asm.LOOPE, x86asm.LOOPNE:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package x86
+
+import (
+	"sort"
+
+	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86/abi"
+)
+
+// Lifter decodes and lifts the x86 instructions of a binary executable.
+//
+// The zero value is not usable; use NewLifter to create a Lifter for a binary
+// executable of a given bitness.
+type Lifter struct {
+	// Function addresses.
+	funcAddrs bin.Addrs
+	// Basic block addresses.
+	blockAddrs bin.Addrs
+	// Maps from basic block address to the set of non-continuous functions
+	// that basic block belongs to.
+	chunks map[bin.Addr]map[bin.Addr]bool
+
+	// Decoded x86 functions.
+	asmFuncs []*Function
+
+	// cpuMode is the x86asm decode mode (16, 32 or 64), derived from the
+	// bitness of the lifted binary executable.
+	cpuMode int
+
+	// sigOverrides maps from function address to a signature sourced from a
+	// JSON override or debug info (PDB/DWARF), taking precedence over the
+	// prologue/epilogue-based inference in decodeFuncs.
+	sigOverrides map[bin.Addr]*abi.Signature
+	// goNames maps from function address to the Go symbol name recovered
+	// from a pclntab, when lifting a Go binary.
+	goNames map[bin.Addr]string
+}
+
+// NewLifter returns a new x86 lifter for a binary executable of the given
+// bitness (16, 32 or 64).
+func NewLifter(bitness int) *Lifter {
+	return &Lifter{
+		cpuMode: bitness,
+	}
+}
+
+// SetFuncAddrs sets the seed function addresses used to delimit functions
+// during decoding, sorting and deduplicating them.
+func (l *Lifter) SetFuncAddrs(funcAddrs bin.Addrs) {
+	l.funcAddrs = dedup(funcAddrs)
+}
+
+// SetBlockAddrs sets the seed basic block addresses used to split basic
+// blocks during decoding, sorting and deduplicating them.
+func (l *Lifter) SetBlockAddrs(blockAddrs bin.Addrs) {
+	l.blockAddrs = dedup(blockAddrs)
+}
+
+// SetChunks sets the non-continuous function chunks, mapping from basic block
+// address to the set of functions that basic block belongs to.
+func (l *Lifter) SetChunks(chunks map[bin.Addr]map[bin.Addr]bool) {
+	l.chunks = chunks
+}
+
+// SetSignatures sets explicit signature overrides (sourced from JSON or
+// PDB/DWARF), taking precedence over prologue/epilogue-based inference.
+func (l *Lifter) SetSignatures(sigs map[bin.Addr]*abi.Signature) {
+	l.sigOverrides = sigs
+}
+
+// SetGoNames sets the Go symbol names (recovered from a pclntab) of
+// functions, keyed by entry address.
+func (l *Lifter) SetGoNames(goNames map[bin.Addr]string) {
+	l.goNames = goNames
+}
+
+// Funcs returns the x86 functions decoded by the lifter.
+func (l *Lifter) Funcs() []*Function {
+	return l.asmFuncs
+}
+
+// dedup returns the sorted addrs with duplicates removed.
+func dedup(addrs bin.Addrs) bin.Addrs {
+	sort.Sort(addrs)
+	out := addrs[:0]
+	for i, addr := range addrs {
+		if i == 0 || addr != out[len(out)-1] {
+			out = append(out, addr)
+		}
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package sem
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// setFlag stores val (an i1) into the named EFLAGS bit.
+func (tr *Translator) setFlag(name string, val value.Value) {
+	tr.emitVoid(ir.NewStore(val, tr.State.Flag(name)))
+}
+
+// loadFlag loads the named EFLAGS bit.
+func (tr *Translator) loadFlag(name string) value.Value {
+	return tr.emit(ir.NewLoad(types.I1, tr.State.Flag(name)))
+}
+
+// not returns the logical negation of the i1 value val.
+func (tr *Translator) not(val value.Value) value.Value {
+	return tr.emit(ir.NewXor(val, constant.True))
+}
+
+// Cond evaluates the boolean condition tested by a conditional jump opcode,
+// as an i1 derived from the EFLAGS state. Only conditions defined purely in
+// terms of flags are supported so far; JCXZ/JECXZ/JRCXZ (which test ECX/RCX)
+// and LOOP/LOOPE/LOOPNE (which test ECX/RCX together with ZF) are left as a
+// TODO.
+func (tr *Translator) Cond(op x86asm.Op) (value.Value, error) {
+	switch op {
+	case x86asm.JE:
+		return tr.loadFlag("ZF"), nil
+	case x86asm.JNE:
+		return tr.not(tr.loadFlag("ZF")), nil
+	case x86asm.JB:
+		return tr.loadFlag("CF"), nil
+	case x86asm.JAE:
+		return tr.not(tr.loadFlag("CF")), nil
+	case x86asm.JBE:
+		return tr.emit(ir.NewOr(tr.loadFlag("CF"), tr.loadFlag("ZF"))), nil
+	case x86asm.JA:
+		notCF := tr.not(tr.loadFlag("CF"))
+		notZF := tr.not(tr.loadFlag("ZF"))
+		return tr.emit(ir.NewAnd(notCF, notZF)), nil
+	case x86asm.JS:
+		return tr.loadFlag("SF"), nil
+	case x86asm.JNS:
+		return tr.not(tr.loadFlag("SF")), nil
+	case x86asm.JP:
+		return tr.loadFlag("PF"), nil
+	case x86asm.JNP:
+		return tr.not(tr.loadFlag("PF")), nil
+	case x86asm.JO:
+		return tr.loadFlag("OF"), nil
+	case x86asm.JNO:
+		return tr.not(tr.loadFlag("OF")), nil
+	case x86asm.JL:
+		return tr.emit(ir.NewICmp(enum.IPredNE, tr.loadFlag("SF"), tr.loadFlag("OF"))), nil
+	case x86asm.JGE:
+		return tr.emit(ir.NewICmp(enum.IPredEQ, tr.loadFlag("SF"), tr.loadFlag("OF"))), nil
+	case x86asm.JLE:
+		sfNeOF := tr.emit(ir.NewICmp(enum.IPredNE, tr.loadFlag("SF"), tr.loadFlag("OF")))
+		return tr.emit(ir.NewOr(tr.loadFlag("ZF"), sfNeOF)), nil
+	case x86asm.JG:
+		sfEqOF := tr.emit(ir.NewICmp(enum.IPredEQ, tr.loadFlag("SF"), tr.loadFlag("OF")))
+		notZF := tr.not(tr.loadFlag("ZF"))
+		return tr.emit(ir.NewAnd(notZF, sfEqOF)), nil
+	default:
+		return nil, errors.Errorf("support for condition of opcode %v not yet implemented", op)
+	}
+}
+
+// updateLogicFlags updates the flags defined after a bitwise instruction
+// (AND, OR, XOR, TEST): CF and OF are cleared, PF, ZF and SF are set from
+// result, and AF is left undefined (modeled here as cleared, matching most
+// real-world decompilers' treatment of an undefined flag).
+func (tr *Translator) updateLogicFlags(result value.Value) {
+	resultType := result.Type().(*types.IntType)
+	zero := constant.NewInt(resultType, 0)
+	tr.setFlag("CF", constant.False)
+	tr.setFlag("OF", constant.False)
+	tr.setFlag("AF", constant.False)
+	tr.setFlag("ZF", tr.emit(ir.NewICmp(enum.IPredEQ, result, zero)))
+	tr.setFlag("SF", tr.emit(ir.NewICmp(enum.IPredSLT, result, zero)))
+	tr.setFlag("PF", tr.parity(result))
+}
+
+// updateArithFlags updates the flags defined after an additive instruction
+// (ADD, SUB, CMP) given its result and the two operands it was computed
+// from: CF, PF, AF, ZF, SF and OF.
+//
+// AF (the auxiliary carry flag, used by BCD arithmetic) is modeled as
+// cleared; it is left as a TODO since no opcode in opTable currently
+// consumes it.
+func (tr *Translator) updateArithFlags(result, dst, src value.Value) {
+	resultType := result.Type().(*types.IntType)
+	zero := constant.NewInt(resultType, 0)
+	tr.setFlag("ZF", tr.emit(ir.NewICmp(enum.IPredEQ, result, zero)))
+	tr.setFlag("SF", tr.emit(ir.NewICmp(enum.IPredSLT, result, zero)))
+	tr.setFlag("PF", tr.parity(result))
+	tr.setFlag("AF", constant.False)
+	// CF: unsigned overflow of the addition (result < dst), or borrow of the
+	// subtraction (dst < src).
+	if _, isSub := result.(*ir.InstSub); isSub {
+		tr.setFlag("CF", tr.emit(ir.NewICmp(enum.IPredULT, dst, src)))
+	} else {
+		tr.setFlag("CF", tr.emit(ir.NewICmp(enum.IPredULT, result, dst)))
+	}
+	// OF: signed overflow; the result has a different sign than both
+	// operands shared (for ADD) or than dst (for SUB).
+	tr.setFlag("OF", tr.signedOverflow(result, dst, src))
+}
+
+// parity computes the x86 PF flag: the parity of the low byte of val (1 if
+// the low byte has an even number of set bits).
+func (tr *Translator) parity(val value.Value) value.Value {
+	low8 := tr.emit(ir.NewTrunc(val, types.I8))
+	var parity value.Value = low8
+	for shift := 1; shift < 8; shift *= 2 {
+		shifted := tr.emit(ir.NewLShr(parity, constant.NewInt(types.I8, int64(shift))))
+		parity = tr.emit(ir.NewXor(parity, shifted))
+	}
+	bit := tr.emit(ir.NewAnd(parity, constant.NewInt(types.I8, 1)))
+	return tr.emit(ir.NewICmp(enum.IPredEQ, bit, constant.NewInt(types.I8, 0)))
+}
+
+// signedOverflow reports, as an i1, whether result = dst +/- src overflowed
+// as a signed integer, from the sign bits of dst, src and result: an ADD
+// overflows when dst and src share a sign that result does not have, a SUB
+// (or CMP) when dst and src differ in sign and result's sign differs from
+// dst's.
+func (tr *Translator) signedOverflow(result, dst, src value.Value) value.Value {
+	resultType := result.Type().(*types.IntType)
+	zero := constant.NewInt(resultType, 0)
+	dstNeg := tr.emit(ir.NewICmp(enum.IPredSLT, dst, zero))
+	srcNeg := tr.emit(ir.NewICmp(enum.IPredSLT, src, zero))
+	resNeg := tr.emit(ir.NewICmp(enum.IPredSLT, result, zero))
+	operandSignPred := enum.IPredEQ
+	if _, isSub := result.(*ir.InstSub); isSub {
+		operandSignPred = enum.IPredNE
+	}
+	operandSigns := tr.emit(ir.NewICmp(operandSignPred, dstNeg, srcNeg))
+	signDiffers := tr.emit(ir.NewICmp(enum.IPredNE, dstNeg, resNeg))
+	return tr.emit(ir.NewAnd(operandSigns, signDiffers))
+}
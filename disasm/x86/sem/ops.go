@@ -0,0 +1,278 @@
+package sem
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/pkg/errors"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// TranslateFunc translates inst into LLVM IR instructions appended to tr's
+// current basic block.
+type TranslateFunc func(tr *Translator, inst x86asm.Inst) error
+
+// opTable maps from x86 opcode to the function that translates it.
+//
+// Only a representative subset of the instruction set is covered; the
+// remaining ~600 opcodes of the Intel SDM are left as a TODO, following the
+// same incremental, opcode-at-a-time approach used throughout disasm/x86.
+var opTable = map[x86asm.Op]TranslateFunc{
+	x86asm.MOV:  translateMOV,
+	x86asm.LEA:  translateLEA,
+	x86asm.ADD:  translateADD,
+	x86asm.SUB:  translateSUB,
+	x86asm.AND:  translateAND,
+	x86asm.OR:   translateOR,
+	x86asm.XOR:  translateXOR,
+	x86asm.CMP:  translateCMP,
+	x86asm.TEST: translateTEST,
+	x86asm.PUSH: translatePUSH,
+	x86asm.POP:  translatePOP,
+	x86asm.NOP:  translateNOP,
+	x86asm.SHL:  translateSHL,
+	x86asm.SHR:  translateSHR,
+}
+
+// translateMOV translates a MOV instruction; it copies Args[1] into Args[0]
+// without affecting EFLAGS.
+func translateMOV(tr *Translator, inst x86asm.Inst) error {
+	src, err := tr.LoadArg(inst, inst.Args[1])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return tr.StoreArg(inst.Args[0], src)
+}
+
+// translateLEA translates a LEA instruction; it stores the effective address
+// of the memory operand Args[1] into the register Args[0], without
+// dereferencing it.
+func translateLEA(tr *Translator, inst x86asm.Inst) error {
+	mem, ok := inst.Args[1].(x86asm.Mem)
+	if !ok {
+		return errors.Errorf("invalid LEA source operand type %T", inst.Args[1])
+	}
+	reg, ok := inst.Args[0].(x86asm.Reg)
+	if !ok {
+		return errors.Errorf("invalid LEA destination operand type %T", inst.Args[0])
+	}
+	addr, err := tr.effectiveAddr(mem)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return tr.StoreReg(reg, addr)
+}
+
+// translateADD translates an ADD instruction; it adds Args[1] to Args[0],
+// storing the result in Args[0] and updating CF, PF, AF, ZF, SF and OF.
+func translateADD(tr *Translator, inst x86asm.Inst) error {
+	dst, src, err := tr.loadBinOp(inst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewAdd(dst, src))
+	tr.updateArithFlags(result, dst, src)
+	return tr.StoreArg(inst.Args[0], result)
+}
+
+// translateSUB translates a SUB instruction; it subtracts Args[1] from
+// Args[0], storing the result in Args[0] and updating CF, PF, AF, ZF, SF and
+// OF.
+func translateSUB(tr *Translator, inst x86asm.Inst) error {
+	dst, src, err := tr.loadBinOp(inst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewSub(dst, src))
+	tr.updateArithFlags(result, dst, src)
+	return tr.StoreArg(inst.Args[0], result)
+}
+
+// translateCMP translates a CMP instruction; it computes Args[0] - Args[1],
+// discarding the result but updating CF, PF, AF, ZF, SF and OF as SUB would.
+func translateCMP(tr *Translator, inst x86asm.Inst) error {
+	dst, src, err := tr.loadBinOp(inst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewSub(dst, src))
+	tr.updateArithFlags(result, dst, src)
+	return nil
+}
+
+// translateAND translates an AND instruction; it bitwise-ANDs Args[1] into
+// Args[0], storing the result in Args[0], clearing CF and OF, and updating
+// PF, ZF and SF.
+func translateAND(tr *Translator, inst x86asm.Inst) error {
+	dst, src, err := tr.loadBinOp(inst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewAnd(dst, src))
+	tr.updateLogicFlags(result)
+	return tr.StoreArg(inst.Args[0], result)
+}
+
+// translateOR translates an OR instruction; it bitwise-ORs Args[1] into
+// Args[0], storing the result in Args[0], clearing CF and OF, and updating
+// PF, ZF and SF.
+func translateOR(tr *Translator, inst x86asm.Inst) error {
+	dst, src, err := tr.loadBinOp(inst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewOr(dst, src))
+	tr.updateLogicFlags(result)
+	return tr.StoreArg(inst.Args[0], result)
+}
+
+// translateXOR translates an XOR instruction; it bitwise-XORs Args[1] into
+// Args[0], storing the result in Args[0], clearing CF and OF, and updating
+// PF, ZF and SF.
+func translateXOR(tr *Translator, inst x86asm.Inst) error {
+	dst, src, err := tr.loadBinOp(inst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewXor(dst, src))
+	tr.updateLogicFlags(result)
+	return tr.StoreArg(inst.Args[0], result)
+}
+
+// translateTEST translates a TEST instruction; it computes Args[0] & Args[1],
+// discarding the result but updating flags as AND would.
+func translateTEST(tr *Translator, inst x86asm.Inst) error {
+	dst, src, err := tr.loadBinOp(inst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewAnd(dst, src))
+	tr.updateLogicFlags(result)
+	return nil
+}
+
+// translatePUSH translates a PUSH instruction; it decrements RSP/ESP by the
+// native register width (4 or 8 bytes) and stores Args[0] at [RSP].
+func translatePUSH(tr *Translator, inst x86asm.Inst) error {
+	val, err := tr.LoadArg(inst, inst.Args[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	sp := spReg(tr.State.regType.BitSize)
+	size := int64(tr.State.regType.BitSize / 8)
+	oldSP, err := tr.LoadReg(sp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	newSP := tr.emit(ir.NewSub(oldSP, constant.NewInt(tr.State.regType, size)))
+	if err := tr.StoreReg(sp, newSP); err != nil {
+		return errors.WithStack(err)
+	}
+	ptr := tr.emit(ir.NewIntToPtr(newSP, types.NewPointer(tr.State.regType)))
+	tr.emitVoid(ir.NewStore(val, ptr))
+	return nil
+}
+
+// translatePOP translates a POP instruction; it loads [RSP] into Args[0] and
+// increments RSP/ESP by the native register width (4 or 8 bytes).
+func translatePOP(tr *Translator, inst x86asm.Inst) error {
+	sp := spReg(tr.State.regType.BitSize)
+	size := int64(tr.State.regType.BitSize / 8)
+	oldSP, err := tr.LoadReg(sp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ptr := tr.emit(ir.NewIntToPtr(oldSP, types.NewPointer(tr.State.regType)))
+	val := tr.emit(ir.NewLoad(tr.State.regType, ptr))
+	newSP := tr.emit(ir.NewAdd(oldSP, constant.NewInt(tr.State.regType, size)))
+	if err := tr.StoreReg(sp, newSP); err != nil {
+		return errors.WithStack(err)
+	}
+	return tr.StoreArg(inst.Args[0], val)
+}
+
+// translateNOP translates a NOP instruction; it has no effect.
+func translateNOP(tr *Translator, inst x86asm.Inst) error {
+	return nil
+}
+
+// translateSHL translates a SHL instruction; it shifts Args[0] left by the
+// count in Args[1], storing the result in Args[0] and updating PF, ZF and
+// SF. CF and OF are conservatively cleared rather than modeling the precise
+// last-bit-shifted-out/sign-change semantics of a real shift (TODO).
+func translateSHL(tr *Translator, inst x86asm.Inst) error {
+	dst, err := tr.LoadArg(inst, inst.Args[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	count, err := tr.loadShiftCount(inst, dst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewShl(dst, count))
+	tr.updateLogicFlags(result)
+	return tr.StoreArg(inst.Args[0], result)
+}
+
+// translateSHR translates a SHR instruction; it shifts Args[0] right
+// (logically) by the count in Args[1], storing the result in Args[0] and
+// updating PF, ZF and SF. CF and OF are conservatively cleared rather than
+// modeling the precise last-bit-shifted-out/sign-change semantics of a real
+// shift (TODO).
+func translateSHR(tr *Translator, inst x86asm.Inst) error {
+	dst, err := tr.LoadArg(inst, inst.Args[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	count, err := tr.loadShiftCount(inst, dst)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	result := tr.emit(ir.NewLShr(dst, count))
+	tr.updateLogicFlags(result)
+	return tr.StoreArg(inst.Args[0], result)
+}
+
+// loadShiftCount loads the shift-count operand of a SHL/SHR instruction
+// (Args[1]) and zero-extends or truncates it to match the bit width of dst.
+func (tr *Translator) loadShiftCount(inst x86asm.Inst, dst value.Value) (value.Value, error) {
+	count, err := tr.LoadArg(inst, inst.Args[1])
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dstType, ok := dst.Type().(*types.IntType)
+	if !ok {
+		return nil, errors.Errorf("invalid shift destination type %T", dst.Type())
+	}
+	countType, ok := count.Type().(*types.IntType)
+	if !ok || countType.BitSize == dstType.BitSize {
+		return count, nil
+	}
+	if countType.BitSize < dstType.BitSize {
+		return tr.emit(ir.NewZExt(count, dstType)), nil
+	}
+	return tr.emit(ir.NewTrunc(count, dstType)), nil
+}
+
+// loadBinOp loads the two operands of a binary ALU instruction of the form
+// `op dst, src`.
+func (tr *Translator) loadBinOp(inst x86asm.Inst) (dst, src value.Value, err error) {
+	dst, err = tr.LoadArg(inst, inst.Args[0])
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	src, err = tr.LoadArg(inst, inst.Args[1])
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return dst, src, nil
+}
+
+// spReg returns the stack pointer register of the given bit width.
+func spReg(width uint64) x86asm.Reg {
+	if width == 32 {
+		return x86asm.ESP
+	}
+	return x86asm.RSP
+}
@@ -0,0 +1,116 @@
+// Package sem models the x86 architectural state (general-purpose
+// registers and EFLAGS) as LLVM IR allocas, and provides a table-driven
+// translator from x86asm instructions to LLVM IR.
+package sem
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/types"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// regFamilies groups the sub-registers that alias the same underlying
+// 64-bit general-purpose register, keyed by the 64-bit register name.
+var regFamilies = map[x86asm.Reg][]x86asm.Reg{
+	x86asm.RAX: {x86asm.RAX, x86asm.EAX, x86asm.AX, x86asm.AL, x86asm.AH},
+	x86asm.RCX: {x86asm.RCX, x86asm.ECX, x86asm.CX, x86asm.CL, x86asm.CH},
+	x86asm.RDX: {x86asm.RDX, x86asm.EDX, x86asm.DX, x86asm.DL, x86asm.DH},
+	x86asm.RBX: {x86asm.RBX, x86asm.EBX, x86asm.BX, x86asm.BL, x86asm.BH},
+	x86asm.RSP: {x86asm.RSP, x86asm.ESP, x86asm.SP},
+	x86asm.RBP: {x86asm.RBP, x86asm.EBP, x86asm.BP},
+	x86asm.RSI: {x86asm.RSI, x86asm.ESI, x86asm.SI},
+	x86asm.RDI: {x86asm.RDI, x86asm.EDI, x86asm.DI},
+}
+
+// regFamily returns the 64-bit register that reg aliases, its bit width, and
+// the bit offset of reg within that 64-bit register.
+func regFamily(reg x86asm.Reg) (family x86asm.Reg, width, offset int) {
+	for fam, members := range regFamilies {
+		for _, member := range members {
+			if member != reg {
+				continue
+			}
+			switch reg {
+			case x86asm.AH, x86asm.CH, x86asm.DH, x86asm.BH:
+				return fam, 8, 8
+			case x86asm.AL, x86asm.CL, x86asm.DL, x86asm.BL:
+				return fam, 8, 0
+			case x86asm.AX, x86asm.CX, x86asm.DX, x86asm.BX, x86asm.SP, x86asm.BP, x86asm.SI, x86asm.DI:
+				return fam, 16, 0
+			case x86asm.EAX, x86asm.ECX, x86asm.EDX, x86asm.EBX, x86asm.ESP, x86asm.EBP, x86asm.ESI, x86asm.EDI:
+				return fam, 32, 0
+			default:
+				return fam, 64, 0
+			}
+		}
+	}
+	return reg, 64, 0
+}
+
+// flagNames are the EFLAGS bits modeled individually as i1 allocas.
+var flagNames = []string{"CF", "PF", "AF", "ZF", "SF", "OF"}
+
+// State holds the LLVM IR allocas backing the x86 architectural state
+// (general-purpose registers and EFLAGS) of a function being lifted.
+//
+// Instructions read and write State's allocas via load/store, in the same
+// spirit as a C compiler's naive (pre-mem2reg) lowering of local variables;
+// a later mem2reg-style pass can promote them to genuine SSA values.
+type State struct {
+	// regType is the integer type backing each 64-bit register family
+	// (i32 on a 32-bit target, i64 on a 64-bit target).
+	regType *types.IntType
+	// regs maps from 64-bit register family (e.g. x86asm.RAX) to its
+	// backing alloca.
+	regs map[x86asm.Reg]*ir.InstAlloca
+	// flags maps from flag name (e.g. "ZF") to its backing i1 alloca.
+	flags map[string]*ir.InstAlloca
+}
+
+// NewState creates the register and flag allocas for a function of the given
+// bitness (32 or 64) at the entry block entry, and returns the resulting
+// State.
+func NewState(entry *ir.Block, bitness int) *State {
+	regType := types.I32
+	if bitness == 64 {
+		regType = types.I64
+	}
+	s := &State{
+		regType: regType,
+		regs:    make(map[x86asm.Reg]*ir.InstAlloca),
+		flags:   make(map[string]*ir.InstAlloca),
+	}
+	for fam := range regFamilies {
+		alloca := ir.NewAlloca(regType)
+		alloca.SetName(regName(fam))
+		entry.Insts = append(entry.Insts, alloca)
+		s.regs[fam] = alloca
+	}
+	for _, name := range flagNames {
+		alloca := ir.NewAlloca(types.I1)
+		alloca.SetName(name)
+		entry.Insts = append(entry.Insts, alloca)
+		s.flags[name] = alloca
+	}
+	return s
+}
+
+// regName returns the LLVM IR local name to use for the alloca backing the
+// given 64-bit register family.
+func regName(fam x86asm.Reg) string {
+	return fam.String()
+}
+
+// Flag returns the alloca backing the named EFLAGS bit (one of "CF", "PF",
+// "AF", "ZF", "SF", "OF").
+func (s *State) Flag(name string) *ir.InstAlloca {
+	return s.flags[name]
+}
+
+// Reg returns the alloca backing the 64-bit register family that reg
+// aliases, along with the bit width and bit offset of reg within that
+// family.
+func (s *State) Reg(reg x86asm.Reg) (alloca *ir.InstAlloca, width, offset int) {
+	fam, width, offset := regFamily(reg)
+	return s.regs[fam], width, offset
+}
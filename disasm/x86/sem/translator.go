@@ -0,0 +1,229 @@
+package sem
+
+import (
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+	"github.com/mewmew/x/bin"
+	"github.com/pkg/errors"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// Translator lowers x86asm instructions into LLVM IR instructions, appended
+// to the current basic block, reading and writing architectural state
+// through a State.
+type Translator struct {
+	// State is the register/flag file of the function being lifted.
+	State *State
+	// Block is the LLVM IR basic block instructions are currently appended
+	// to.
+	Block *ir.Block
+	// Addr is the address of the instruction currently being translated,
+	// used to resolve PC-relative operands (x86asm.Rel).
+	Addr bin.Addr
+}
+
+// NewTranslator returns a new translator emitting into block, backed by
+// state.
+func NewTranslator(state *State, block *ir.Block) *Translator {
+	return &Translator{State: state, Block: block}
+}
+
+// emit appends inst to the current basic block and returns inst's result
+// value.
+func (tr *Translator) emit(inst interface {
+	ir.Instruction
+	value.Value
+}) value.Value {
+	tr.Block.Insts = append(tr.Block.Insts, inst)
+	return inst
+}
+
+// emitVoid appends inst to the current basic block; used for instructions
+// such as store, which produce no result value.
+func (tr *Translator) emitVoid(inst ir.Instruction) {
+	tr.Block.Insts = append(tr.Block.Insts, inst)
+}
+
+// Translate lowers the instruction inst, located at addr, into LLVM IR,
+// dispatching through opTable.
+func (tr *Translator) Translate(addr bin.Addr, inst x86asm.Inst) error {
+	tr.Addr = addr
+	fn, ok := opTable[inst.Op]
+	if !ok {
+		return errors.Errorf("support for opcode %v not yet implemented in x86/sem", inst.Op)
+	}
+	return fn(tr, inst)
+}
+
+// ### [ Register access ] #####################################################
+
+// LoadReg returns the value currently held in reg, as an integer of reg's
+// own bit width (e.g. loading AL yields an i8, loading EAX an i32).
+func (tr *Translator) LoadReg(reg x86asm.Reg) (value.Value, error) {
+	alloca, width, offset := tr.State.Reg(reg)
+	if alloca == nil {
+		return nil, errors.Errorf("unsupported register %v", reg)
+	}
+	full := tr.emit(ir.NewLoad(alloca.ElemType, alloca))
+	regType := intType(width)
+	if uint64(width) == alloca.ElemType.(*types.IntType).BitSize {
+		return full, nil
+	}
+	shifted := full
+	if offset != 0 {
+		shifted = tr.emit(ir.NewLShr(full, constant.NewInt(alloca.ElemType.(*types.IntType), int64(offset))))
+	}
+	return tr.emit(ir.NewTrunc(shifted, regType)), nil
+}
+
+// StoreReg stores val (an integer of reg's own bit width) into reg,
+// preserving the other bits of the 64-bit register family reg aliases
+// (except for 32-bit destination writes, which the x86-64 ABI defines as
+// zero-extending the full 64-bit register).
+func (tr *Translator) StoreReg(reg x86asm.Reg, val value.Value) error {
+	alloca, width, offset := tr.State.Reg(reg)
+	if alloca == nil {
+		return errors.Errorf("unsupported register %v", reg)
+	}
+	famType := alloca.ElemType.(*types.IntType)
+	if uint64(width) == famType.BitSize {
+		tr.emitVoid(ir.NewStore(val, alloca))
+		return nil
+	}
+	if width == 32 && famType.BitSize == 64 {
+		// Writing a 32-bit register zero-extends into the full 64-bit
+		// register (e.g. `mov eax, ...` clears the upper 32 bits of rax).
+		zext := tr.emit(ir.NewZExt(val, famType))
+		tr.emitVoid(ir.NewStore(zext, alloca))
+		return nil
+	}
+	full := tr.emit(ir.NewLoad(famType, alloca))
+	mask := int64(-1) &^ (((int64(1) << uint(width)) - 1) << uint(offset))
+	cleared := tr.emit(ir.NewAnd(full, constant.NewInt(famType, mask)))
+	zext := tr.emit(ir.NewZExt(val, famType))
+	var positioned value.Value = zext
+	if offset != 0 {
+		positioned = tr.emit(ir.NewShl(zext, constant.NewInt(famType, int64(offset))))
+	}
+	merged := tr.emit(ir.NewOr(cleared, positioned))
+	tr.emitVoid(ir.NewStore(merged, alloca))
+	return nil
+}
+
+// ### [ Operand access ] #######################################################
+
+// LoadArg evaluates arg for read, returning its value. inst is the
+// instruction arg belongs to, used to resolve PC-relative operands (via
+// inst.Len) and to size immediate/memory operands (via inst.DataSize/
+// inst.MemBytes), since x86asm.Imm and x86asm.Mem carry no width of their
+// own.
+func (tr *Translator) LoadArg(inst x86asm.Inst, arg x86asm.Arg) (value.Value, error) {
+	switch a := arg.(type) {
+	case x86asm.Reg:
+		return tr.LoadReg(a)
+	case x86asm.Imm:
+		return constant.NewInt(intType(inst.DataSize), int64(a)), nil
+	case x86asm.Mem:
+		addr, err := tr.effectiveAddr(a)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		width := inst.MemBytes * 8
+		if width == 0 {
+			width = int(tr.State.regType.BitSize)
+		}
+		elemType := intType(width)
+		ptr := tr.emit(ir.NewIntToPtr(addr, types.NewPointer(elemType)))
+		return tr.emit(ir.NewLoad(elemType, ptr)), nil
+	case x86asm.Rel:
+		return constant.NewInt(types.I64, int64(tr.Addr)+int64(inst.Len)+int64(a)), nil
+	default:
+		return nil, errors.Errorf("support for operand type %T not yet implemented", arg)
+	}
+}
+
+// StoreArg writes val to arg, which must be a register or memory operand.
+// For a memory operand, the store width is taken from val's own type (set by
+// whichever LoadArg/ALU op produced it), not re-derived from the
+// instruction.
+func (tr *Translator) StoreArg(arg x86asm.Arg, val value.Value) error {
+	switch a := arg.(type) {
+	case x86asm.Reg:
+		return tr.StoreReg(a, val)
+	case x86asm.Mem:
+		addr, err := tr.effectiveAddr(a)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		valType, ok := val.Type().(*types.IntType)
+		if !ok {
+			return errors.Errorf("invalid memory store value type %T", val.Type())
+		}
+		ptr := tr.emit(ir.NewIntToPtr(addr, types.NewPointer(valType)))
+		tr.emitVoid(ir.NewStore(val, ptr))
+		return nil
+	default:
+		return errors.Errorf("support for write to operand type %T not yet implemented", arg)
+	}
+}
+
+// effectiveAddr computes base + index*scale + disp for a memory operand and
+// returns it as a regType-width integer address; callers cast it to a
+// pointer of the width appropriate for their access (LoadArg/StoreArg size
+// it from the instruction/value being transferred, LEA stores it unchanged).
+//
+// Segment overrides (FS/GS-relative addressing, used e.g. for TLS access)
+// are not yet modeled.
+func (tr *Translator) effectiveAddr(mem x86asm.Mem) (value.Value, error) {
+	addrType := tr.State.regType
+	var addr value.Value = constant.NewInt(addrType, mem.Disp)
+	if mem.Base != 0 {
+		base, err := tr.LoadReg(mem.Base)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		addr = tr.emit(ir.NewAdd(addr, tr.widenToAddr(base, addrType)))
+	}
+	if mem.Index != 0 {
+		index, err := tr.LoadReg(mem.Index)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		index = tr.widenToAddr(index, addrType)
+		if mem.Scale > 1 {
+			index = tr.emit(ir.NewMul(index, constant.NewInt(addrType, int64(mem.Scale))))
+		}
+		addr = tr.emit(ir.NewAdd(addr, index))
+	}
+	return addr, nil
+}
+
+// widenToAddr zero-extends val, a just-loaded register value, to addrType's
+// bit width if it is narrower (e.g. a 32-bit base register used for
+// addressing in 64-bit mode via an address-size override prefix); val is
+// returned unchanged if it is already addrType's width, the common case.
+// Without this, adding a narrower base/index into the addrType-width
+// accumulator produces an LLVM IR add of mismatched integer widths.
+func (tr *Translator) widenToAddr(val value.Value, addrType *types.IntType) value.Value {
+	valType, ok := val.Type().(*types.IntType)
+	if !ok || valType.BitSize == addrType.BitSize {
+		return val
+	}
+	return tr.emit(ir.NewZExt(val, addrType))
+}
+
+// intType returns the LLVM IR integer type of the given bit width.
+func intType(width int) *types.IntType {
+	switch width {
+	case 8:
+		return types.I8
+	case 16:
+		return types.I16
+	case 32:
+		return types.I32
+	default:
+		return types.I64
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/mewkiz/pkg/term"
 	"github.com/mewmew/x/bin"
+	"github.com/mewmew/x/disasm/x86/abi"
 	"github.com/pkg/errors"
 	"golang.org/x/arch/x86/x86asm"
 )
@@ -32,6 +33,15 @@ type Function struct {
 	// Map from basic block address to basic block, containing one or more basic
 	// blocks.
 	Blocks map[bin.Addr]*BasicBlock
+	// Signature is the (possibly inferred) calling convention and parameter
+	// and return types of the function. It is populated by decodeFuncs
+	// unless overridden by a more authoritative source (JSON override,
+	// PDB/DWARF).
+	Signature *abi.Signature
+	// GoName is the symbol name recovered from a Go binary's pclntab (e.g.
+	// "main.main"), or "" if the function is not known to belong to a Go
+	// binary.
+	GoName string
 }
 
 // newFunc returns a new function.
@@ -42,6 +52,16 @@ func newFunc(entry bin.Addr) *Function {
 	}
 }
 
+// Name returns the display name of the function: its recovered Go name
+// (e.g. "main.main") if known, or else the generic "func_%08X" placeholder
+// derived from its entry address.
+func (f *Function) Name() string {
+	if f.GoName != "" {
+		return f.GoName
+	}
+	return fmt.Sprintf("func_%08X", uint32(f.Entry))
+}
+
 // String returns the string representation of the function.
 func (f *Function) String() string {
 	buf := &bytes.Buffer{}
@@ -87,6 +107,11 @@ func (block *BasicBlock) Entry() bin.Addr {
 	return block.insts[0].addr
 }
 
+// Insts returns the instructions of the basic block.
+func (block *BasicBlock) Insts() []*Instruction {
+	return block.insts
+}
+
 // Instruction is an x86 instruction.
 type Instruction struct {
 	// Address of instruction.
@@ -95,10 +120,15 @@ type Instruction struct {
 	x86asm.Inst
 }
 
-// decodeCodeSection decodes the x86 instructions of the given code section.
+// Addr returns the address of the instruction.
+func (inst *Instruction) Addr() bin.Addr {
+	return inst.addr
+}
+
+// DecodeCodeSection decodes the x86 instructions of the given code section.
 //
 // Post-condition: l.asmFuncs contains the decoded x86 functions.
-func (l *lifter) decodeCodeSection(start bin.Addr, data []byte) error {
+func (l *Lifter) DecodeCodeSection(start bin.Addr, data []byte) error {
 	blocks, err := l.decodeBlocks(start, data)
 	if err != nil {
 		return errors.WithStack(err)
@@ -112,7 +142,7 @@ func (l *lifter) decodeCodeSection(start bin.Addr, data []byte) error {
 }
 
 // decodeFuncs decodes the x86 functions based on the given basic blocks.
-func (l *lifter) decodeFuncs(blocks []*BasicBlock) ([]*Function, error) {
+func (l *Lifter) decodeFuncs(blocks []*BasicBlock) ([]*Function, error) {
 	dbg.Println("decodeFuncs(blocks)")
 	// Add continuous basic blocks.
 	j := 0
@@ -120,7 +150,7 @@ func (l *lifter) decodeFuncs(blocks []*BasicBlock) ([]*Function, error) {
 	funcFromAddr := make(map[bin.Addr]*Function)
 	for i, funcAddr := range l.funcAddrs {
 		start := funcAddr
-		end := bin.Addr(math.MaxUint32)
+		end := bin.Addr(math.MaxUint64)
 		if i+1 < len(l.funcAddrs) {
 			end = l.funcAddrs[i+1]
 		}
@@ -133,11 +163,11 @@ func (l *lifter) decodeFuncs(blocks []*BasicBlock) ([]*Function, error) {
 			if blockAddr < start {
 				return nil, errors.Errorf("unable to locate function containing basic block; expected address >= %v, got %v", start, blockAddr)
 			}
-			f.blocks[blockAddr] = block
+			f.Blocks[blockAddr] = block
 			j++
 		}
 		funcs = append(funcs, f)
-		funcFromAddr[f.entry] = f
+		funcFromAddr[f.Entry] = f
 	}
 	// Add non-continuous basic blocks.
 	if len(l.chunks) > 0 {
@@ -156,18 +186,58 @@ func (l *lifter) decodeFuncs(blocks []*BasicBlock) ([]*Function, error) {
 				if !ok {
 					return nil, errors.Errorf("unable to locate function at %v", funcAddr)
 				}
-				f.blocks[blockAddr] = block
+				f.Blocks[blockAddr] = block
 			}
 		}
 	}
 	//for _, f := range funcs {
 	//	dbg.Println(f)
 	//}
+	// Infer a calling convention and signature for each function, unless a
+	// more authoritative source (JSON override, PDB/DWARF) has already set
+	// one.
+	for _, f := range funcs {
+		if sig, ok := l.sigOverrides[f.Entry]; ok {
+			f.Signature = sig
+		} else {
+			f.Signature = l.inferSignature(f)
+		}
+		f.GoName = l.goNames[f.Entry]
+	}
 	return funcs, nil
 }
 
+// inferSignature infers the signature of f from the prologue of its entry
+// block and the epilogue of a basic block terminated by a RET instruction.
+func (l *Lifter) inferSignature(f *Function) *abi.Signature {
+	entry, ok := f.Blocks[f.Entry]
+	if !ok {
+		return abi.NewSignature()
+	}
+	var ret []*Instruction
+	for _, block := range f.Blocks {
+		if len(block.insts) == 0 {
+			continue
+		}
+		if last := block.insts[len(block.insts)-1]; last.Op == x86asm.RET {
+			ret = block.insts
+			break
+		}
+	}
+	return abi.Infer(l.cpuMode, rawInsts(entry.insts), rawInsts(ret))
+}
+
+// rawInsts returns the underlying x86asm.Inst values of insts.
+func rawInsts(insts []*Instruction) []x86asm.Inst {
+	out := make([]x86asm.Inst, len(insts))
+	for i, inst := range insts {
+		out[i] = inst.Inst
+	}
+	return out
+}
+
 // decodeBlocks decodes the x86 basic blocks of the given section.
-func (l *lifter) decodeBlocks(start bin.Addr, data []byte) ([]*BasicBlock, error) {
+func (l *Lifter) decodeBlocks(start bin.Addr, data []byte) ([]*BasicBlock, error) {
 	var blocks []*BasicBlock
 	//dbg.Printf("decodeBlocks(start = %v, data)", start)
 	for j, blockAddr := range l.blockAddrs {
@@ -195,8 +265,8 @@ func (l *lifter) decodeBlocks(start bin.Addr, data []byte) ([]*BasicBlock, error
 
 // decodeInst decodes the leading bytes in src as a single x86 instruction, and
 // annotates the instruction with the given address.
-func (l *lifter) decodeInst(instAddr bin.Addr, src []byte) (*Instruction, error) {
-	inst, err := x86asm.Decode(src, cpuMode)
+func (l *Lifter) decodeInst(instAddr bin.Addr, src []byte) (*Instruction, error) {
+	inst, err := x86asm.Decode(src, l.cpuMode)
 	if err != nil {
 		end := 16
 		if end > len(src) {